@@ -0,0 +1,244 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package maintenance
+
+import (
+	"context"
+	"io/ioutil"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/autonomy/talos/internal/app/init/internal/shutdown"
+	"github.com/autonomy/talos/internal/app/init/pkg/boot"
+	"github.com/autonomy/talos/internal/app/osd/pkg/rules"
+	"github.com/autonomy/talos/internal/app/osd/proto"
+	"github.com/autonomy/talos/internal/pkg/constants"
+	"github.com/autonomy/talos/internal/pkg/grpc/middleware/auth/rbac"
+	"github.com/autonomy/talos/internal/pkg/userdata"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/empty"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// StopTimeout is the time the in flight requests are given to complete when
+// the boot sequence is retried.
+const StopTimeout = 5 * time.Second
+
+// Server is the concrete type that implements the proto.OSDServer and
+// proto.MaintenanceServer interfaces while the node is in maintenance mode.
+// Only the methods that help diagnose a failed boot are implemented by the
+// OSD service.
+type Server struct {
+	// Failure is the error that failed the boot sequence.
+	Failure error
+	// Timeline is the timeline of the failed boot sequence, if any.
+	Timeline *boot.Timeline
+	// Data is the user data read before the failure, if any. The node CA
+	// and identity are taken from it.
+	Data *userdata.UserData
+	// UserDataPath is the path that corrected user data is written to.
+	UserDataPath string
+	// RebootFunc reboots the node.
+	RebootFunc func(...shutdown.Option)
+	// PoweroffFunc powers off the node.
+	PoweroffFunc func(...shutdown.Option)
+	// Insecure allows clients that are not authenticated to call the
+	// methods that change the node. Without it, such clients can only read
+	// the state of the node.
+	Insecure bool
+
+	since         time.Time
+	authenticated bool
+
+	mu      sync.Mutex
+	applied bool
+	retry   chan struct{}
+}
+
+// Serve serves the maintenance API on the osd port until a retry of the boot
+// sequence is requested. It reports whether corrected user data was applied.
+func (s *Server) Serve() (applied bool, err error) {
+	s.since = time.Now()
+	s.retry = make(chan struct{})
+
+	config, authenticated, err := s.config()
+	if err != nil {
+		return false, err
+	}
+	s.authenticated = authenticated
+
+	listener, err := net.Listen("tcp", ":"+strconv.Itoa(constants.OsdPort))
+	if err != nil {
+		return false, err
+	}
+
+	opts := []grpc.ServerOption{grpc.Creds(credentials.NewTLS(config))}
+	// The clients can only be authorized when they are authenticated.
+	// Otherwise, they are limited to the read only methods.
+	if authenticated {
+		a := &rbac.Authorizer{Rules: rules.Rules}
+		opts = append(opts, grpc.UnaryInterceptor(a.UnaryInterceptor), grpc.StreamInterceptor(a.StreamInterceptor))
+	} else {
+		opts = append(opts, grpc.UnaryInterceptor(s.unaryInterceptor), grpc.StreamInterceptor(s.streamInterceptor))
+	}
+	server := grpc.NewServer(opts...)
+	s.Register(server)
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- server.Serve(listener)
+	}()
+
+	log.Printf("serving the maintenance API on port %d", constants.OsdPort)
+
+	select {
+	case err = <-errCh:
+		return false, err
+	case <-s.retry:
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(StopTimeout):
+		server.Stop()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.applied, nil
+}
+
+// unauthenticatedMethods are the methods that clients that are not
+// authenticated can call. They only read the state of the node.
+var unauthenticatedMethods = map[string]struct{}{
+	"/proto.Maintenance/MaintenanceStatus": {},
+	"/proto.OSD/BootTimeline":              {},
+	"/proto.OSD/Dmesg":                     {},
+	"/proto.OSD/DmesgStream":               {},
+	"/proto.OSD/Version":                   {},
+}
+
+// authorizeUnauthenticated rejects the methods that change the node, unless
+// insecure access was opted in. Applying user data chooses what the node
+// boots with, so it must not be open to anyone who can reach the node.
+func (s *Server) authorizeUnauthenticated(method string) error {
+	if s.Insecure {
+		return nil
+	}
+	if _, ok := unauthenticatedMethods[method]; ok {
+		return nil
+	}
+
+	return status.Errorf(codes.PermissionDenied, "%s requires an authenticated client, and the node has no CA to authenticate clients with", method)
+}
+
+func (s *Server) unaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if err := s.authorizeUnauthenticated(info.FullMethod); err != nil {
+		return nil, err
+	}
+
+	return handler(ctx, req)
+}
+
+func (s *Server) streamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := s.authorizeUnauthenticated(info.FullMethod); err != nil {
+		return err
+	}
+
+	return handler(srv, ss)
+}
+
+// Register implements the factory.Registrator interface.
+func (s *Server) Register(server *grpc.Server) {
+	proto.RegisterOSDServer(server, s)
+	proto.RegisterMaintenanceServer(server, s)
+}
+
+// MaintenanceStatus implements the proto.MaintenanceServer interface.
+func (s *Server) MaintenanceStatus(ctx context.Context, in *empty.Empty) (reply *proto.MaintenanceStatusReply, err error) {
+	reply = &proto.MaintenanceStatusReply{
+		Authenticated: s.authenticated,
+	}
+	if reply.Since, err = ptypes.TimestampProto(s.since); err != nil {
+		return nil, err
+	}
+
+	if s.Failure != nil {
+		reply.Error = s.Failure.Error()
+	}
+	if e, ok := s.Failure.(*boot.Error); ok {
+		reply.Phase = e.Phase
+		reply.Task = e.Task
+		reply.Error = e.Err.Error()
+	}
+
+	s.mu.Lock()
+	reply.UserdataApplied = s.applied
+	s.mu.Unlock()
+
+	return reply, nil
+}
+
+// ApplyUserData implements the proto.MaintenanceServer interface. The user
+// data is validated by unmarshaling it, and is used when the boot sequence is
+// retried.
+func (s *Server) ApplyUserData(ctx context.Context, in *proto.ApplyUserDataRequest) (reply *proto.ApplyUserDataReply, err error) {
+	data := &userdata.UserData{}
+	if err = yaml.Unmarshal(in.Userdata, data); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "unmarshal user data: %v", err)
+	}
+
+	if err = os.MkdirAll(filepath.Dir(s.UserDataPath), 0700); err != nil {
+		return nil, err
+	}
+	// The file may exist with read only permissions.
+	if err = os.Remove(s.UserDataPath); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if err = ioutil.WriteFile(s.UserDataPath, in.Userdata, 0400); err != nil {
+		return nil, err
+	}
+
+	log.Printf("applied corrected user data to %s", s.UserDataPath)
+
+	s.mu.Lock()
+	s.applied = true
+	s.mu.Unlock()
+
+	return &proto.ApplyUserDataReply{}, nil
+}
+
+// RetryBoot implements the proto.MaintenanceServer interface. The maintenance
+// API stops once the reply is sent, and the boot sequence is retried.
+func (s *Server) RetryBoot(ctx context.Context, in *empty.Empty) (reply *proto.RetryBootReply, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	select {
+	case <-s.retry:
+		return nil, status.Error(codes.FailedPrecondition, "a retry is already in progress")
+	default:
+		close(s.retry)
+	}
+
+	log.Println("retrying the boot sequence")
+
+	return &proto.RetryBootReply{}, nil
+}
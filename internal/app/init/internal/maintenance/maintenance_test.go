@@ -0,0 +1,34 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package maintenance
+
+import (
+	"testing"
+)
+
+// nolint: scopelint
+func TestServer_authorizeUnauthenticated(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		insecure bool
+		wantErr  bool
+	}{
+		{"read", "/proto.Maintenance/MaintenanceStatus", false, false},
+		{"stream", "/proto.OSD/DmesgStream", false, false},
+		{"apply", "/proto.Maintenance/ApplyUserData", false, true},
+		{"retry", "/proto.Maintenance/RetryBoot", false, true},
+		{"reboot", "/proto.OSD/Reboot", false, true},
+		{"insecure apply", "/proto.Maintenance/ApplyUserData", true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Server{Insecure: tt.insecure}
+			if err := s.authorizeUnauthenticated(tt.method); (err != nil) != tt.wantErr {
+				t.Errorf("authorizeUnauthenticated() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
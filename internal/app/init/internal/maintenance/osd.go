@@ -0,0 +1,236 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package maintenance
+
+import (
+	"context"
+	"time"
+
+	"github.com/autonomy/talos/internal/app/init/internal/shutdown"
+	"github.com/autonomy/talos/internal/app/osd/proto"
+	"github.com/autonomy/talos/internal/pkg/constants"
+	"github.com/autonomy/talos/internal/pkg/kernel/kmsg"
+	"github.com/autonomy/talos/internal/pkg/version"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/empty"
+	"golang.org/x/sys/unix"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// errUnavailable is returned by the methods of the OSD service that require
+// a booted node.
+var errUnavailable = status.Error(codes.Unavailable, "node is in maintenance mode")
+
+// BootTimeline implements the proto.OSDServer interface.
+func (s *Server) BootTimeline(ctx context.Context, in *empty.Empty) (reply *proto.BootTimelineReply, err error) {
+	if s.Timeline == nil {
+		return nil, status.Error(codes.NotFound, "boot timeline not found")
+	}
+
+	return s.Timeline.Proto()
+}
+
+// Dmesg implements the proto.OSDServer interface. Init logs to the kernel
+// ring buffer, so the boot logs are included.
+func (s *Server) Dmesg(ctx context.Context, in *empty.Empty) (data *proto.Data, err error) {
+	// Return the size of the kernel ring buffer
+	size, err := unix.Klogctl(constants.SYSLOG_ACTION_SIZE_BUFFER, nil)
+	if err != nil {
+		return
+	}
+	// Read all messages from the log (non-destructively)
+	buf := make([]byte, size)
+	n, err := unix.Klogctl(constants.SYSLOG_ACTION_READ_ALL, buf)
+	if err != nil {
+		return
+	}
+
+	data = &proto.Data{Bytes: buf[:n]}
+
+	return data, err
+}
+
+// DmesgStream implements the proto.OSDServer interface.
+func (s *Server) DmesgStream(req *proto.DmesgRequest, stream proto.OSD_DmesgStreamServer) error {
+	level := len(kmsg.Priorities) - 1
+	if req.Level != "" {
+		var err error
+		if level, err = kmsg.ParsePriority(req.Level); err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+
+	records, err := kmsg.Read(stream.Context(), req.Follow)
+	if err != nil {
+		return err
+	}
+
+	for record := range records {
+		if record.Priority > level {
+			continue
+		}
+
+		ts, err := ptypes.TimestampProto(record.Timestamp)
+		if err != nil {
+			return err
+		}
+
+		err = stream.Send(&proto.DmesgRecord{
+			Facility:  uint32(record.Facility),
+			Priority:  uint32(record.Priority),
+			Sequence:  record.Sequence,
+			Monotonic: int64(record.Monotonic / time.Microsecond),
+			Timestamp: ts,
+			Message:   record.Message,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Version implements the proto.OSDServer interface.
+func (s *Server) Version(ctx context.Context, in *empty.Empty) (data *proto.Data, err error) {
+	v, err := version.NewVersion()
+	if err != nil {
+		return
+	}
+
+	data = &proto.Data{Bytes: []byte(v)}
+
+	return data, err
+}
+
+// Reboot implements the proto.OSDServer interface. The node is rebooted in
+// the background, so that the reply can be sent.
+func (s *Server) Reboot(ctx context.Context, in *proto.RebootRequest) (reply *proto.RebootReply, err error) {
+	if s.RebootFunc == nil {
+		return nil, errUnavailable
+	}
+
+	opts, err := shutdown.ParseOptions(in.Delay, in.Force)
+	if err != nil {
+		return nil, err
+	}
+
+	go s.RebootFunc(opts...)
+
+	return &proto.RebootReply{}, nil
+}
+
+// Shutdown implements the proto.OSDServer interface. The node is powered off
+// in the background, so that the reply can be sent.
+func (s *Server) Shutdown(ctx context.Context, in *proto.ShutdownRequest) (reply *proto.ShutdownReply, err error) {
+	if s.PoweroffFunc == nil {
+		return nil, errUnavailable
+	}
+
+	opts, err := shutdown.ParseOptions(in.Delay, in.Force)
+	if err != nil {
+		return nil, err
+	}
+
+	go s.PoweroffFunc(opts...)
+
+	return &proto.ShutdownReply{}, nil
+}
+
+// Kubeconfig implements the proto.OSDServer interface.
+func (s *Server) Kubeconfig(ctx context.Context, in *empty.Empty) (*proto.Data, error) {
+	return nil, errUnavailable
+}
+
+// HostProcesses implements the proto.OSDServer interface.
+func (s *Server) HostProcesses(ctx context.Context, in *empty.Empty) (*proto.HostProcessesReply, error) {
+	return nil, errUnavailable
+}
+
+// Interfaces implements the proto.OSDServer interface.
+func (s *Server) Interfaces(ctx context.Context, in *empty.Empty) (*proto.InterfacesReply, error) {
+	return nil, errUnavailable
+}
+
+// ListFiles implements the proto.OSDServer interface.
+func (s *Server) ListFiles(ctx context.Context, in *proto.ListFilesRequest) (*proto.ListFilesReply, error) {
+	return nil, errUnavailable
+}
+
+// ReadFile implements the proto.OSDServer interface.
+func (s *Server) ReadFile(req *proto.ReadFileRequest, stream proto.OSD_ReadFileServer) error {
+	return errUnavailable
+}
+
+// CopyOut implements the proto.OSDServer interface.
+func (s *Server) CopyOut(req *proto.CopyOutRequest, stream proto.OSD_CopyOutServer) error {
+	return errUnavailable
+}
+
+// Logs implements the proto.OSDServer interface.
+func (s *Server) Logs(req *proto.LogsRequest, stream proto.OSD_LogsServer) error {
+	return errUnavailable
+}
+
+// Mounts implements the proto.OSDServer interface.
+func (s *Server) Mounts(ctx context.Context, in *empty.Empty) (*proto.MountsReply, error) {
+	return nil, errUnavailable
+}
+
+// Processes implements the proto.OSDServer interface.
+func (s *Server) Processes(ctx context.Context, in *proto.ProcessesRequest) (*proto.ProcessesReply, error) {
+	return nil, errUnavailable
+}
+
+// Reset implements the proto.OSDServer interface.
+func (s *Server) Reset(ctx context.Context, in *empty.Empty) (*proto.ResetReply, error) {
+	return nil, errUnavailable
+}
+
+// Restart implements the proto.OSDServer interface.
+func (s *Server) Restart(ctx context.Context, in *proto.RestartRequest) (*proto.RestartReply, error) {
+	return nil, errUnavailable
+}
+
+// Routes implements the proto.OSDServer interface.
+func (s *Server) Routes(ctx context.Context, in *proto.RoutesRequest) (*proto.RoutesReply, error) {
+	return nil, errUnavailable
+}
+
+// ServiceInfo implements the proto.OSDServer interface.
+func (s *Server) ServiceInfo(ctx context.Context, in *proto.ServiceInfoRequest) (*proto.ServiceInfoReply, error) {
+	return nil, errUnavailable
+}
+
+// ServiceList implements the proto.OSDServer interface.
+func (s *Server) ServiceList(ctx context.Context, in *empty.Empty) (*proto.ServiceListReply, error) {
+	return nil, errUnavailable
+}
+
+// ServiceRestart implements the proto.OSDServer interface.
+func (s *Server) ServiceRestart(ctx context.Context, in *proto.ServiceRestartRequest) (*proto.ServiceRestartReply, error) {
+	return nil, errUnavailable
+}
+
+// ServiceStart implements the proto.OSDServer interface.
+func (s *Server) ServiceStart(ctx context.Context, in *proto.ServiceStartRequest) (*proto.ServiceStartReply, error) {
+	return nil, errUnavailable
+}
+
+// ServiceStop implements the proto.OSDServer interface.
+func (s *Server) ServiceStop(ctx context.Context, in *proto.ServiceStopRequest) (*proto.ServiceStopReply, error) {
+	return nil, errUnavailable
+}
+
+// SystemStat implements the proto.OSDServer interface.
+func (s *Server) SystemStat(in *proto.SystemStatRequest, srv proto.OSD_SystemStatServer) error {
+	return errUnavailable
+}
+
+// Stats implements the proto.OSDServer interface.
+func (s *Server) Stats(ctx context.Context, in *proto.StatsRequest) (*proto.StatsReply, error) {
+	return nil, errUnavailable
+}
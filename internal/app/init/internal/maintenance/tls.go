@@ -0,0 +1,88 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package maintenance
+
+import (
+	"crypto/tls"
+	"log"
+	"time"
+
+	"github.com/autonomy/talos/internal/pkg/constants"
+	"github.com/autonomy/talos/internal/pkg/crypto/x509"
+	grpctls "github.com/autonomy/talos/internal/pkg/grpc/tls"
+	"github.com/autonomy/talos/internal/pkg/net"
+	"github.com/autonomy/talos/internal/pkg/userdata"
+)
+
+// config returns the TLS configuration of the maintenance API, and whether
+// clients are authenticated. Clients are authenticated with the node CA when
+// the user data provides one. The node identity is used when it exists, and
+// is otherwise issued by the node CA, or self-signed as a last resort.
+func (s *Server) config() (config *tls.Config, authenticated bool, err error) {
+	var ca, identity *x509.PEMEncodedCertificateAndKey
+	if s.Data != nil && s.Data.Security != nil && s.Data.Security.OS != nil {
+		ca = s.Data.Security.OS.CA
+		identity = s.Data.Security.OS.Identity
+	}
+
+	if identity == nil || len(identity.Crt) == 0 || len(identity.Key) == 0 {
+		if identity, err = s.identity(ca); err != nil {
+			return nil, false, err
+		}
+	}
+
+	if ca == nil || len(ca.Crt) == 0 {
+		if s.Insecure {
+			log.Println("WARNING no node CA was found, the maintenance API does not authenticate clients, and allows them to change the node")
+		} else {
+			log.Println("WARNING no node CA was found, the maintenance API does not authenticate clients, and only allows them to read the state of the node")
+		}
+		config, err = grpctls.NewConfig(grpctls.ServerOnly, &userdata.OSSecurity{CA: identity, Identity: identity})
+		return config, false, err
+	}
+
+	config, err = grpctls.NewConfig(grpctls.Mutual, &userdata.OSSecurity{CA: ca, Identity: identity})
+
+	return config, true, err
+}
+
+// identity issues a certificate from the CA when its key is available, and
+// otherwise generates a self-signed certificate. Clients must skip the
+// verification of a self-signed certificate.
+func (s *Server) identity(ca *x509.PEMEncodedCertificateAndKey) (*x509.PEMEncodedCertificateAndKey, error) {
+	if ca != nil && len(ca.Crt) != 0 && len(ca.Key) != 0 {
+		// The identity is generated on a copy, so that the boot sequence
+		// generates its own when it is retried.
+		data := *s.Data
+		security := *data.Security
+		osSecurity := *security.OS
+		security.OS = &osSecurity
+		data.Security = &security
+
+		csr, err := data.NewIdentityCSR()
+		if err != nil {
+			return nil, err
+		}
+		crt, err := x509.NewCertificateFromCSRBytes(ca.Crt, ca.Key, csr.X509CertificateRequestPEM, x509.NotAfter(time.Now().Add(24*time.Hour)), x509.Organization(constants.ControlPlaneOrganization))
+		if err != nil {
+			return nil, err
+		}
+
+		return &x509.PEMEncodedCertificateAndKey{Crt: crt.X509CertificatePEM, Key: osSecurity.Identity.Key}, nil
+	}
+
+	log.Println("WARNING no node identity was found, the maintenance API uses a self-signed certificate")
+
+	ips, err := net.IPAddrs()
+	if err != nil {
+		return nil, err
+	}
+	crt, err := x509.NewSelfSignedCertificateAuthority(x509.IPAddresses(ips), x509.NotAfter(time.Now().Add(24*time.Hour)))
+	if err != nil {
+		return nil, err
+	}
+
+	return &x509.PEMEncodedCertificateAndKey{Crt: crt.CrtPEM, Key: crt.KeyPEM}, nil
+}
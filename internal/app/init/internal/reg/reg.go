@@ -0,0 +1,257 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package reg
+
+import (
+	"context"
+	"log"
+
+	"github.com/autonomy/talos/internal/app/init/internal/rootfs/mount"
+	"github.com/autonomy/talos/internal/app/init/internal/shutdown"
+	"github.com/autonomy/talos/internal/app/init/pkg/system"
+	"github.com/autonomy/talos/internal/app/init/pkg/system/health"
+	"github.com/autonomy/talos/internal/app/init/proto"
+	pkgmount "github.com/autonomy/talos/internal/pkg/mount"
+	"github.com/autonomy/talos/internal/pkg/proc"
+	"github.com/autonomy/talos/internal/pkg/userdata"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/empty"
+	"golang.org/x/sys/unix"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Registrator is the concrete type that implements the factory.Registrator and
+// proto.InitServer interfaces.
+type Registrator struct {
+	Data *userdata.UserData
+}
+
+// Register implements the factory.Registrator interface.
+func (r *Registrator) Register(s *grpc.Server) {
+	proto.RegisterInitServer(s, r)
+}
+
+// Reboot implements the proto.InitServer interface. The shutdown sequence
+// runs in the background, since it stops the services that may be waiting on
+// the reply.
+func (r *Registrator) Reboot(ctx context.Context, in *proto.RebootRequest) (reply *proto.RebootReply, err error) {
+	opts, err := shutdown.ParseOptions(in.Delay, in.Force)
+	if err != nil {
+		return nil, err
+	}
+
+	go shutdown.Reboot(r.Data, opts...)
+
+	return &proto.RebootReply{}, nil
+}
+
+// Shutdown implements the proto.InitServer interface. The shutdown sequence
+// runs in the background, and then the node is powered off.
+func (r *Registrator) Shutdown(ctx context.Context, in *proto.ShutdownRequest) (reply *proto.ShutdownReply, err error) {
+	opts, err := shutdown.ParseOptions(in.Delay, in.Force)
+	if err != nil {
+		return nil, err
+	}
+
+	go shutdown.Poweroff(r.Data, opts...)
+
+	return &proto.ShutdownReply{}, nil
+}
+
+// Mounts implements the proto.InitServer interface. The mount table of init
+// is the mount table of the host. The usage of every filesystem is read with
+// statfs, and is left empty if that fails.
+func (r *Registrator) Mounts(ctx context.Context, in *empty.Empty) (reply *proto.MountsReply, err error) {
+	entries, err := pkgmount.Table()
+	if err != nil {
+		return nil, err
+	}
+
+	owned, err := mount.OwnedMountPoints()
+	if err != nil {
+		log.Printf("failed to find the owned mount points: %v", err)
+		owned = pkgmount.NewMountPoints()
+	}
+
+	reply = &proto.MountsReply{}
+	for _, entry := range entries {
+		stat := &proto.MountStat{
+			Source:  entry.Source,
+			Target:  entry.Target,
+			Fstype:  entry.Fstype,
+			Options: entry.Options,
+		}
+
+		var statfs unix.Statfs_t
+		if err := unix.Statfs(entry.Target, &statfs); err == nil {
+			// nolint: unconvert
+			bsize := uint64(statfs.Bsize)
+			stat.Size = statfs.Blocks * bsize
+			stat.Used = (statfs.Blocks - statfs.Bfree) * bsize
+			stat.Available = statfs.Bavail * bsize
+			stat.Inodes = statfs.Files
+			stat.InodesFree = statfs.Ffree
+		}
+
+		iter := owned.Iter()
+		for iter.Next() {
+			if iter.Value().Source() == entry.Source && iter.Value().Target() == entry.Target {
+				stat.Owned = true
+				stat.Label = iter.Key()
+			}
+		}
+
+		reply.Stats = append(reply.Stats, stat)
+	}
+
+	return reply, nil
+}
+
+// HostProcesses implements the proto.InitServer interface. The processes are
+// read from the proc filesystem of init, which is in the PID namespace of the
+// host.
+func (r *Registrator) HostProcesses(ctx context.Context, in *empty.Empty) (reply *proto.HostProcessesReply, err error) {
+	processes, err := proc.ReadProcesses()
+	if err != nil {
+		return nil, err
+	}
+
+	reply = &proto.HostProcessesReply{}
+	for _, p := range processes {
+		reply.Processes = append(reply.Processes, &proto.HostProcess{
+			Pid:            p.Pid,
+			Ppid:           p.PPid,
+			State:          p.State,
+			Command:        p.Command,
+			Args:           p.Args,
+			Executable:     p.Executable,
+			ResidentMemory: p.ResidentMemory,
+			VirtualMemory:  p.VirtualMemory,
+			CpuTime:        ptypes.DurationProto(p.CPUTime),
+			Threads:        p.Threads,
+			Cgroup:         p.Cgroup,
+		})
+	}
+
+	return reply, nil
+}
+
+// ServiceList implements the proto.InitServer interface.
+func (r *Registrator) ServiceList(ctx context.Context, in *empty.Empty) (reply *proto.ServiceListReply, err error) {
+	runners := system.Services(r.Data).List()
+
+	reply = &proto.ServiceListReply{
+		Services: make([]*proto.ServiceInfo, len(runners)),
+	}
+
+	for i, runner := range runners {
+		if reply.Services[i], err = serviceInfo(runner); err != nil {
+			return nil, err
+		}
+	}
+
+	return reply, nil
+}
+
+// ServiceInfo implements the proto.InitServer interface.
+func (r *Registrator) ServiceInfo(ctx context.Context, in *proto.ServiceInfoRequest) (reply *proto.ServiceInfoReply, err error) {
+	runner, ok := system.Services(r.Data).Get(in.Id)
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "service %q not found", in.Id)
+	}
+
+	reply = &proto.ServiceInfoReply{}
+	if reply.Service, err = serviceInfo(runner); err != nil {
+		return nil, err
+	}
+
+	return reply, nil
+}
+
+// ServiceStart implements the proto.InitServer interface.
+func (r *Registrator) ServiceStart(ctx context.Context, in *proto.ServiceStartRequest) (reply *proto.ServiceStartReply, err error) {
+	if err = system.Services(r.Data).StartService(in.Id); err != nil {
+		return nil, serviceError(in.Id, err)
+	}
+
+	return &proto.ServiceStartReply{}, nil
+}
+
+// ServiceStop implements the proto.InitServer interface.
+func (r *Registrator) ServiceStop(ctx context.Context, in *proto.ServiceStopRequest) (reply *proto.ServiceStopReply, err error) {
+	if err = system.Services(r.Data).StopService(in.Id); err != nil {
+		return nil, serviceError(in.Id, err)
+	}
+
+	return &proto.ServiceStopReply{}, nil
+}
+
+// ServiceRestart implements the proto.InitServer interface.
+func (r *Registrator) ServiceRestart(ctx context.Context, in *proto.ServiceRestartRequest) (reply *proto.ServiceRestartReply, err error) {
+	if err = system.Services(r.Data).RestartService(in.Id); err != nil {
+		return nil, serviceError(in.Id, err)
+	}
+
+	return &proto.ServiceRestartReply{}, nil
+}
+
+// serviceError maps the errors returned by the system services API to gRPC
+// status errors.
+func serviceError(id string, err error) error {
+	switch err {
+	case system.ErrServiceNotFound:
+		return status.Errorf(codes.NotFound, "service %q not found", id)
+	case system.ErrServiceRunning, system.ErrServiceNotRunning:
+		return status.Errorf(codes.FailedPrecondition, "service %q: %v", id, err)
+	default:
+		return err
+	}
+}
+
+func serviceInfo(runner *system.ServiceRunner) (info *proto.ServiceInfo, err error) {
+	info = &proto.ServiceInfo{
+		Id:       runner.ID(),
+		State:    runner.State().String(),
+		Restarts: uint32(runner.RestartCount()),
+	}
+
+	for _, event := range runner.Events() {
+		ts, err := ptypes.TimestampProto(event.Timestamp)
+		if err != nil {
+			return nil, err
+		}
+
+		info.Events = append(info.Events, &proto.ServiceEvent{
+			Msg:   event.Message,
+			State: event.State.String(),
+			Ts:    ts,
+		})
+	}
+
+	if info.Health, err = serviceHealth(runner.Health()); err != nil {
+		return nil, err
+	}
+
+	return info, nil
+}
+
+func serviceHealth(status health.Status) (*proto.ServiceHealth, error) {
+	if status.Healthy == nil {
+		return &proto.ServiceHealth{Unknown: true}, nil
+	}
+
+	ts, err := ptypes.TimestampProto(status.LastChange)
+	if err != nil {
+		return nil, err
+	}
+
+	return &proto.ServiceHealth{
+		Healthy:     *status.Healthy,
+		LastMessage: status.LastMessage,
+		LastChange:  ts,
+	}, nil
+}
@@ -161,6 +161,12 @@ func (i *Initializer) UnmountOwned() (err error) {
 	return nil
 }
 
+// OwnedMountPoints returns the mount points of the OS owned block devices,
+// without mounting them.
+func OwnedMountPoints() (*mount.Points, error) {
+	return mountpoints()
+}
+
 // Switch moves the root to a specified directory. See
 // https://github.com/karelzak/util-linux/blob/master/sys-utils/switch_root.c.
 // nolint: gocyclo
@@ -88,14 +88,16 @@ func Prepare(s string, data *userdata.UserData) (err error) {
 
 func generatePKI(data *userdata.UserData) (err error) {
 	log.Println("generating node identity PKI")
-	if data.IsBootstrap() {
+	// Only the nodes given the OS CA key by the cluster owner sign their own
+	// identity, and are trusted to forward any role of a client.
+	if data.Security.OS.CA != nil && len(data.Security.OS.CA.Key) != 0 {
 		log.Println("generating PKI locally")
 		var csr *x509.CertificateSigningRequest
 		if csr, err = data.NewIdentityCSR(); err != nil {
 			return err
 		}
 		var crt *x509.Certificate
-		crt, err = x509.NewCertificateFromCSRBytes(data.Security.OS.CA.Crt, data.Security.OS.CA.Key, csr.X509CertificateRequestPEM, x509.NotAfter(time.Now().Add(time.Duration(8760)*time.Hour)))
+		crt, err = x509.NewCertificateFromCSRBytes(data.Security.OS.CA.Crt, data.Security.OS.CA.Key, csr.X509CertificateRequestPEM, x509.NotAfter(time.Now().Add(time.Duration(8760)*time.Hour)), x509.Organization(constants.ControlPlaneOrganization))
 		if err != nil {
 			return err
 		}
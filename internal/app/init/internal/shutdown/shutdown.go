@@ -0,0 +1,329 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package shutdown
+
+import (
+	"bufio"
+	"context"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/autonomy/talos/internal/app/init/internal/rootfs/mount"
+	"github.com/autonomy/talos/internal/app/init/pkg/system"
+	"github.com/autonomy/talos/internal/pkg/constants"
+	pkgmount "github.com/autonomy/talos/internal/pkg/mount"
+	"github.com/autonomy/talos/internal/pkg/userdata"
+	"github.com/containerd/containerd"
+	"github.com/containerd/containerd/defaults"
+	"github.com/containerd/containerd/namespaces"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/duration"
+	"github.com/pkg/errors"
+	"golang.org/x/sys/unix"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// ServiceStopTimeout is the time each service is given to stop.
+	ServiceStopTimeout = 30 * time.Second
+	// TaskStopTimeout is the time the remaining containerd tasks are given to
+	// exit after they have been sent SIGTERM, before they are sent SIGKILL.
+	TaskStopTimeout = 10 * time.Second
+)
+
+var once sync.Once
+
+// Options is the functional options struct.
+type Options struct {
+	Delay time.Duration
+	Force bool
+}
+
+// Option is the functional option func.
+type Option func(*Options)
+
+// WithDelay sets the time to wait before the shutdown sequence runs.
+func WithDelay(o time.Duration) Option {
+	return func(args *Options) {
+		args.Delay = o
+	}
+}
+
+// WithForce skips stopping the services and unmounting the partitions. The
+// filesystems are still synced.
+func WithForce(o bool) Option {
+	return func(args *Options) {
+		args.Force = o
+	}
+}
+
+// NewDefaultOptions initializes the Options struct with default values.
+func NewDefaultOptions(setters ...Option) *Options {
+	opts := &Options{}
+
+	for _, setter := range setters {
+		setter(opts)
+	}
+
+	return opts
+}
+
+// ParseOptions converts the delay and force fields of a reboot or shutdown
+// request to options. A negative or malformed delay is an InvalidArgument
+// error.
+func ParseOptions(delay *duration.Duration, force bool) ([]Option, error) {
+	opts := []Option{WithForce(force)}
+	if delay != nil {
+		d, err := ptypes.Duration(delay)
+		if err != nil || d < 0 {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid delay: %v", delay)
+		}
+		opts = append(opts, WithDelay(d))
+	}
+
+	return opts, nil
+}
+
+// Reboot runs the shutdown sequence, and then reboots the node.
+func Reboot(data *userdata.UserData, setters ...Option) {
+	run(data, unix.LINUX_REBOOT_CMD_RESTART, setters...)
+}
+
+// Poweroff runs the shutdown sequence, and then powers off the node.
+func Poweroff(data *userdata.UserData, setters ...Option) {
+	run(data, unix.LINUX_REBOOT_CMD_POWER_OFF, setters...)
+}
+
+// run stops the system services in reverse dependency order, kills the
+// containerd tasks left behind by the services, syncs the filesystems, and
+// unmounts the OS owned partitions before invoking the reboot syscall with
+// the specified command. The sequence runs at most once. Failures are logged,
+// and do not prevent the remaining steps from running. A forced run does not
+// wait for a sequence that is already running.
+func run(data *userdata.UserData, cmd int, setters ...Option) {
+	opts := NewDefaultOptions(setters...)
+
+	if opts.Delay > 0 {
+		log.Printf("running the shutdown sequence in %s", opts.Delay)
+		time.Sleep(opts.Delay)
+	}
+
+	if opts.Force {
+		log.Println("skipping the shutdown sequence")
+		unix.Sync()
+		reboot(cmd)
+		return
+	}
+
+	once.Do(func() {
+		log.Println("running the shutdown sequence")
+
+		stopServices(data)
+
+		log.Println("syncing filesystems")
+		unix.Sync()
+
+		if err := unmountOwned(); err != nil {
+			log.Printf("failed to unmount the owned partitions: %v", err)
+		}
+
+		unix.Sync()
+
+		reboot(cmd)
+	})
+}
+
+func reboot(cmd int) {
+	if err := unix.Reboot(cmd); err != nil {
+		log.Printf("reboot syscall failed: %v", err)
+	}
+}
+
+// stopServices stops the system services, dependents first. The containerd
+// tasks that are not owned by a system service, such as the Kubernetes pods,
+// are killed before containerd is stopped.
+func stopServices(data *userdata.UserData) {
+	svcs := system.Services(data)
+
+	order, err := svcs.ShutdownOrder()
+	if err != nil {
+		log.Printf("failed to determine the shutdown order: %v", err)
+		return
+	}
+
+	for _, id := range order {
+		if id == "containerd" {
+			if err := killTasks(); err != nil {
+				log.Printf("failed to kill the containerd tasks: %v", err)
+			}
+		}
+
+		log.Printf("stopping service %q", id)
+
+		errC := make(chan error, 1)
+		go func(id string) {
+			errC <- svcs.StopService(id)
+		}(id)
+
+		select {
+		case err := <-errC:
+			if err != nil && err != system.ErrServiceNotRunning {
+				log.Printf("failed to stop service %q: %v", id, err)
+			}
+		case <-time.After(ServiceStopTimeout):
+			log.Printf("service %q did not stop within %s", id, ServiceStopTimeout)
+		}
+	}
+}
+
+// killTasks sends SIGTERM to the tasks in every containerd namespace, and
+// SIGKILL to those that do not exit in time.
+func killTasks() error {
+	client, err := containerd.New(defaults.DefaultAddress)
+	if err != nil {
+		return err
+	}
+	// nolint: errcheck
+	defer client.Close()
+
+	nss, err := client.NamespaceService().List(context.Background())
+	if err != nil {
+		return err
+	}
+
+	var wg sync.WaitGroup
+	for _, ns := range nss {
+		ctx := namespaces.WithNamespace(context.Background(), ns)
+
+		containers, err := client.Containers(ctx)
+		if err != nil {
+			log.Printf("failed to list containers in namespace %q: %v", ns, err)
+			continue
+		}
+
+		for _, container := range containers {
+			task, err := container.Task(ctx, nil)
+			if err != nil {
+				// The container has no running task.
+				continue
+			}
+
+			wg.Add(1)
+			go func(ctx context.Context, id string, task containerd.Task) {
+				defer wg.Done()
+				if err := killTask(ctx, task); err != nil {
+					log.Printf("failed to kill task %q: %v", id, err)
+				}
+			}(ctx, container.ID(), task)
+		}
+	}
+	wg.Wait()
+
+	return nil
+}
+
+func killTask(ctx context.Context, task containerd.Task) error {
+	statusC, err := task.Wait(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err = task.Kill(ctx, unix.SIGTERM, containerd.WithKillAll); err != nil {
+		return err
+	}
+
+	select {
+	case <-statusC:
+	case <-time.After(TaskStopTimeout):
+		if err = task.Kill(ctx, unix.SIGKILL, containerd.WithKillAll); err != nil {
+			return err
+		}
+		<-statusC
+	}
+
+	_, err = task.Delete(ctx)
+
+	return err
+}
+
+// unmountOwned unmounts the OS owned partitions in the reverse of the order
+// in which they were mounted. Any mount points nested under a partition are
+// unmounted first. A partition that cannot be unmounted is remounted
+// read-only so that its filesystem is left clean. The root partition is
+// already mounted read-only, and is left as is.
+func unmountOwned() (err error) {
+	owned, err := mount.OwnedMountPoints()
+	if err != nil {
+		return err
+	}
+
+	iter := owned.IterRev()
+	for iter.Next() {
+		if iter.Key() == constants.RootPartitionLabel {
+			continue
+		}
+
+		target := iter.Value().Target()
+		log.Printf("unmounting %s", target)
+
+		if err = unmountNested(target); err != nil {
+			log.Printf("failed to unmount the mount points under %s: %v", target, err)
+		}
+
+		if err = pkgmount.UnWithRetry(iter.Value()); err != nil {
+			log.Printf("failed to unmount %s, remounting it read-only: %v", target, err)
+			if err = unix.Mount("", target, "", unix.MS_REMOUNT|unix.MS_RDONLY, ""); err != nil {
+				return errors.Errorf("error remounting %s read-only: %v", target, err)
+			}
+		}
+	}
+	if iter.Err() != nil {
+		return iter.Err()
+	}
+
+	return nil
+}
+
+// unmountNested unmounts the mount points nested under the target, deepest
+// first.
+func unmountNested(target string) error {
+	f, err := os.Open("/proc/self/mounts")
+	if err != nil {
+		return err
+	}
+	// nolint: errcheck
+	defer f.Close()
+
+	nested := []string{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		if strings.HasPrefix(fields[1], strings.TrimSuffix(target, "/")+"/") {
+			nested = append(nested, fields[1])
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return err
+	}
+
+	// Longer paths are nested deeper.
+	sort.Slice(nested, func(i, j int) bool { return len(nested[i]) > len(nested[j]) })
+
+	for _, mountpoint := range nested {
+		if err = unix.Unmount(mountpoint, unix.MNT_DETACH); err != nil && err != unix.EINVAL {
+			log.Printf("failed to unmount %s: %v", mountpoint, err)
+		}
+	}
+
+	return nil
+}
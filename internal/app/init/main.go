@@ -5,20 +5,31 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
+	"io/ioutil"
 	"log"
 	"os"
 	"time"
 
+	"github.com/autonomy/talos/internal/app/init/internal/maintenance"
 	"github.com/autonomy/talos/internal/app/init/internal/platform"
+	"github.com/autonomy/talos/internal/app/init/internal/reg"
 	"github.com/autonomy/talos/internal/app/init/internal/rootfs"
 	"github.com/autonomy/talos/internal/app/init/internal/rootfs/mount"
+	"github.com/autonomy/talos/internal/app/init/internal/shutdown"
+	"github.com/autonomy/talos/internal/app/init/pkg/boot"
+	"github.com/autonomy/talos/internal/app/init/pkg/logforward"
+	"github.com/autonomy/talos/internal/app/init/pkg/logrotate"
 	"github.com/autonomy/talos/internal/app/init/pkg/network"
 	"github.com/autonomy/talos/internal/app/init/pkg/system"
 	ctrdrunner "github.com/autonomy/talos/internal/app/init/pkg/system/runner/containerd"
+	processlogger "github.com/autonomy/talos/internal/app/init/pkg/system/runner/process/log"
 	"github.com/autonomy/talos/internal/app/init/pkg/system/services"
 	"github.com/autonomy/talos/internal/pkg/constants"
+	"github.com/autonomy/talos/internal/pkg/grpc/factory"
+	"github.com/autonomy/talos/internal/pkg/kernel"
 	"github.com/autonomy/talos/internal/pkg/userdata"
 	"github.com/containerd/containerd"
 	criconstants "github.com/containerd/cri/pkg/constants"
@@ -50,101 +61,329 @@ func kmsg(prefix string) (*os.File, error) {
 
 // nolint: gocyclo
 func initram() (err error) {
-	var initializer *mount.Initializer
-	if initializer, err = mount.NewInitializer(constants.NewRoot); err != nil {
-		return err
-	}
-	// Mount the special devices.
-	if err = initializer.InitSpecial(); err != nil {
-		return err
-	}
-	// Setup logging to /dev/kmsg.
-	_, err = kmsg("[talos] [initramfs]")
-	if err != nil {
-		return err
-	}
-	// Discover the platform.
-	log.Println("discovering the platform")
-	var p platform.Platform
-	if p, err = platform.NewPlatform(); err != nil {
-		return err
-	}
-	log.Printf("platform is: %s", p.Name())
-	// Setup the network.
-	if err = network.Setup(p.Name()); err != nil {
-		return err
-	}
-	// Retrieve the user data.
-	log.Printf("retrieving the user data")
-	var data *userdata.UserData
-	if data, err = p.UserData(); err != nil {
-		log.Printf("encountered error reading userdata: %v", err)
-		return err
-	}
-	// Perform any tasks required by a particular platform.
-	log.Printf("performing platform specific tasks")
-	if err = p.Prepare(data); err != nil {
-		return err
-	}
-	// Mount the owned partitions.
-	log.Printf("mounting the partitions")
-	if err = initializer.InitOwned(); err != nil {
-		return err
-	}
-	// Install handles additional system setup
-	if err = p.Install(data); err != nil {
-		return err
-	}
-	// Prepare the necessary files in the rootfs.
-	log.Println("preparing the root filesystem")
-	if err = rootfs.Prepare(constants.NewRoot, data); err != nil {
-		return err
-	}
-	// Perform the equivalent of switch_root.
-	log.Println("entering the new root")
-	if err = initializer.Switch(); err != nil {
-		return err
+	var (
+		initializer *mount.Initializer
+		p           platform.Platform
+		data        *userdata.UserData
+	)
+
+	seq := boot.NewSequencer(constants.BootTimelinePath)
+
+	phases := []boot.Phase{
+		{
+			Name: "platform discovery",
+			Tasks: []boot.Task{
+				{Name: "mount special filesystems", Func: func() (err error) {
+					if initializer, err = mount.NewInitializer(constants.NewRoot); err != nil {
+						return err
+					}
+					return initializer.InitSpecial()
+				}},
+				{Name: "set up logging", Func: func() (err error) {
+					// Setup logging to /dev/kmsg.
+					_, err = kmsg("[talos] [initramfs]")
+					return err
+				}},
+				{Name: "discover the platform", Func: func() (err error) {
+					log.Println("discovering the platform")
+					if p, err = platform.NewPlatform(); err != nil {
+						return err
+					}
+					log.Printf("platform is: %s", p.Name())
+					return nil
+				}},
+			},
+		},
+		{
+			Name: "network",
+			Tasks: []boot.Task{
+				{Name: "set up the network", Func: func() error {
+					return network.Setup(p.Name())
+				}},
+			},
+		},
+		{
+			Name: "userdata",
+			Tasks: []boot.Task{
+				{Name: "retrieve the user data", Func: func() (err error) {
+					// Prefer the user data applied in maintenance mode.
+					if _, err = os.Stat(constants.UserDataPath); err == nil {
+						log.Printf("reading the user data: %s", constants.UserDataPath)
+						data, err = userdata.Open(constants.UserDataPath)
+						return err
+					}
+					log.Printf("retrieving the user data")
+					if data, err = p.UserData(); err != nil {
+						log.Printf("encountered error reading userdata: %v", err)
+						return err
+					}
+					return nil
+				}},
+				{Name: "perform platform specific tasks", Func: func() error {
+					log.Printf("performing platform specific tasks")
+					return p.Prepare(data)
+				}},
+			},
+		},
+		{
+			Name: "mount",
+			Tasks: []boot.Task{
+				{Name: "mount the owned partitions", Func: func() error {
+					log.Printf("mounting the partitions")
+					return initializer.InitOwned()
+				}, Undo: func() error {
+					log.Printf("unmounting the partitions")
+					return initializer.UnmountOwned()
+				}},
+			},
+		},
+		{
+			Name: "partitioning",
+			Tasks: []boot.Task{
+				// Install handles additional system setup
+				{Name: "install", Func: func() error {
+					return p.Install(data)
+				}},
+			},
+		},
+		{
+			Name: "rootfs prep",
+			Tasks: []boot.Task{
+				{Name: "prepare the root filesystem", Func: func() error {
+					log.Println("preparing the root filesystem")
+					return rootfs.Prepare(constants.NewRoot, data)
+				}},
+			},
+		},
+		{
+			Name: "switch-root",
+			Tasks: []boot.Task{
+				// Perform the equivalent of switch_root. On success, the
+				// task is ended by the sequencer of the new root.
+				{Name: "enter the new root", Func: func() error {
+					log.Println("entering the new root")
+					return initializer.Switch()
+				}},
+			},
+		},
 	}
 
-	return nil
+	return runBoot(seq, phases, func() *userdata.UserData { return data })
 }
 
 func root() (err error) {
-	// Setup logging to /dev/kmsg.
-	if _, err = kmsg("[talos]"); err != nil {
-		return fmt.Errorf("failed to setup logging to /dev/kmsg: %v", err)
-	}
-	// Read the user data.
-	log.Printf("reading the user data: %s\n", constants.UserDataPath)
 	var data *userdata.UserData
-	if data, err = userdata.Open(constants.UserDataPath); err != nil {
-		return err
-	}
 
-	// Write any user specified files to disk.
-	log.Println("writing the files specified in the user data to disk")
-	if err = data.WriteFiles(); err != nil {
-		return err
+	seq := boot.NewSequencer(constants.BootTimelinePath)
+	seq.Resume()
+
+	phases := []boot.Phase{
+		{
+			Name: "root",
+			Tasks: []boot.Task{
+				{Name: "set up logging", Func: func() error {
+					// Setup logging to /dev/kmsg.
+					if _, err := kmsg("[talos]"); err != nil {
+						return fmt.Errorf("failed to setup logging to /dev/kmsg: %v", err)
+					}
+					// Protect init from the OOM killer. The host processes it
+					// starts are given their own OOM score adjustments.
+					if err := ioutil.WriteFile("/proc/self/oom_score_adj", []byte("-1000"), 0644); err != nil {
+						log.Printf("WARNING failed to set the OOM score adjustment of init: %v", err)
+					}
+					return nil
+				}},
+			},
+		},
+		{
+			Name: "userdata",
+			Tasks: []boot.Task{
+				{Name: "read the user data", Func: func() (err error) {
+					log.Printf("reading the user data: %s\n", constants.UserDataPath)
+					data, err = userdata.Open(constants.UserDataPath)
+					return err
+				}},
+				{Name: "write files", Func: func() error {
+					// Write any user specified files to disk.
+					log.Println("writing the files specified in the user data to disk")
+					return data.WriteFiles()
+				}},
+				{Name: "set environment variables", Func: func() error {
+					// Set the requested environment variables.
+					log.Println("setting environment variables")
+					for key, val := range data.Env {
+						if err := os.Setenv(key, val); err != nil {
+							log.Printf("WARNING failed to set enivronment variable: %v", err)
+						}
+					}
+					return nil
+				}},
+			},
+		},
+		{
+			Name: "services",
+			Tasks: []boot.Task{
+				{Name: "start services", Func: func() error {
+					startServices(data)
+					return nil
+				}},
+			},
+		},
 	}
 
-	// Set the requested environment variables.
-	log.Println("setting environment variables")
-	for key, val := range data.Env {
-		if err = os.Setenv(key, val); err != nil {
-			log.Printf("WARNING failed to set enivronment variable: %v", err)
+	return runBoot(seq, phases, func() *userdata.UserData { return data })
+}
+
+// runBoot runs the boot sequence. When it fails, the node enters maintenance
+// mode until a retry is requested, unless rebooting was opted in with the
+// boot failure kernel parameter. Corrected user data invalidates the tasks of
+// the userdata phase and of the phases that follow it, and undoes those that
+// cannot run twice.
+func runBoot(seq *boot.Sequencer, phases []boot.Phase, data func() *userdata.UserData) error {
+	for {
+		err := seq.Run(phases...)
+		if err == nil {
+			return nil
+		}
+		if bootFailureAction() == constants.BootFailureReboot {
+			return err
+		}
+
+		log.Printf("boot failed, entering maintenance mode: %v", err)
+		s := &maintenance.Server{
+			Failure:      err,
+			Timeline:     seq.Timeline(),
+			Data:         data(),
+			UserDataPath: constants.UserDataPath,
+			RebootFunc: func(opts ...shutdown.Option) {
+				shutdown.Reboot(data(), opts...)
+			},
+			PoweroffFunc: func(opts ...shutdown.Option) {
+				shutdown.Poweroff(data(), opts...)
+			},
+			Insecure: maintenanceInsecure(),
+		}
+		applied, err := s.Serve()
+		if err != nil {
+			return errors.Wrap(err, "failed to serve the maintenance API")
 		}
+		if applied {
+			if err = seq.Invalidate("userdata"); err != nil {
+				return errors.Wrap(err, "failed to invalidate the boot sequence")
+			}
+		}
+	}
+}
+
+// bootFailureAction returns the action requested with the boot failure
+// kernel parameter.
+func bootFailureAction() string {
+	arguments, err := kernel.ParseProcCmdline()
+	if err != nil {
+		return constants.BootFailureMaintenance
+	}
+	if action, ok := arguments[constants.KernelParamBootFailure]; ok && action == constants.BootFailureReboot {
+		return constants.BootFailureReboot
 	}
 
+	return constants.BootFailureMaintenance
+}
+
+// maintenanceInsecure returns true if clients that are not authenticated are
+// allowed to change the node in maintenance mode.
+func maintenanceInsecure() bool {
+	arguments, err := kernel.ParseProcCmdline()
+	if err != nil {
+		return false
+	}
+
+	return arguments[constants.KernelParamMaintenanceInsecure] == "true"
+}
+
+func startServices(data *userdata.UserData) {
 	// Get a handle to the system services API.
 	svcs := system.Services(data)
 
+	// Reboot the node if a crash looping service asks for it.
+	svcs.SetRebootFunc(func() { shutdown.Reboot(data) })
+
+	// Serve the init API.
+	go startInitAPI(data)
+
+	// Rotate the service logs.
+	go logrotate.New(logRotateOptions(data)...).Run(context.Background())
+
+	// Forward the logs to the configured sinks.
+	go forwardLogs(data)
+
 	// Start containerd.
 	svcs.Start(&services.Containerd{})
 
 	go startSystemServices(data)
 	go startKubernetesServices(data)
+}
 
-	return nil
+func startInitAPI(data *userdata.UserData) {
+	err := factory.Listen(
+		&reg.Registrator{Data: data},
+		factory.Network("unix"),
+		factory.SocketPath(constants.InitSocketPath),
+	)
+	if err != nil {
+		log.Printf("failed to serve the init API: %v", err)
+	}
+}
+
+func forwardLogs(data *userdata.UserData) {
+	if data.Services == nil || data.Services.Init == nil || data.Services.Init.Logging == nil {
+		return
+	}
+
+	forwarder, err := logforward.New(
+		data.Services.Init.Logging.Sinks,
+		logforward.WithNamespace(func(id string) string {
+			// Services with a log registered by the process runner run on
+			// the host. The others run in containerd.
+			if processlogger.Exists(id) {
+				return ""
+			}
+			if svcrunner, ok := system.Services(data).Get(id); ok {
+				return svcrunner.Namespace()
+			}
+			return constants.SystemContainerdNamespace
+		}),
+	)
+	if err != nil {
+		log.Printf("failed to forward logs: %v", err)
+		return
+	}
+
+	forwarder.Run(context.Background())
+}
+
+func logRotateOptions(data *userdata.UserData) (opts []logrotate.Option) {
+	if data.Services == nil || data.Services.Init == nil || data.Services.Init.Logging == nil {
+		return nil
+	}
+
+	logging := data.Services.Init.Logging
+	if logging.MaxSize > 0 {
+		opts = append(opts, logrotate.WithMaxSize(logging.MaxSize))
+	}
+	if logging.Generations != nil {
+		opts = append(opts, logrotate.WithGenerations(*logging.Generations))
+	}
+	if logging.Compress != nil {
+		opts = append(opts, logrotate.WithCompress(*logging.Compress))
+	}
+	if logging.ServiceQuota > 0 {
+		opts = append(opts, logrotate.WithServiceQuota(logging.ServiceQuota))
+	}
+	if logging.Budget > 0 {
+		opts = append(opts, logrotate.WithBudget(logging.Budget))
+	}
+
+	return opts
 }
 
 func startSystemServices(data *userdata.UserData) {
@@ -197,6 +436,22 @@ func startSystemServices(data *userdata.UserData) {
 			&services.Proxyd{},
 		)
 	}
+	// Start the extension services declared in the user data.
+	if data.Services != nil && len(data.Services.Extensions) > 0 {
+		extensions := make([]system.Service, 0, len(data.Services.Extensions))
+		for _, spec := range data.Services.Extensions {
+			if spec == nil {
+				continue
+			}
+			extension := &services.Extension{Spec: spec}
+			if err := extension.Validate(); err != nil {
+				log.Printf("skipping extension: %v", err)
+				continue
+			}
+			extensions = append(extensions, extension)
+		}
+		svcs.Start(extensions...)
+	}
 }
 
 func startKubernetesServices(data *userdata.UserData) {
@@ -232,6 +487,20 @@ func startKubernetesServices(data *userdata.UserData) {
 func recovery() {
 	if r := recover(); r != nil {
 		log.Printf("recovered from: %+v\n", r)
+		if bootFailureAction() != constants.BootFailureReboot {
+			// The boot sequence cannot be resumed, so a retry reboots the
+			// node.
+			s := &maintenance.Server{
+				Failure:      fmt.Errorf("%v", r),
+				UserDataPath: constants.UserDataPath,
+				Insecure:     maintenanceInsecure(),
+			}
+			s.Timeline, _ = boot.Load(constants.BootTimelinePath)
+			s.Data, _ = userdata.Open(constants.UserDataPath)
+			if _, err := s.Serve(); err != nil {
+				log.Printf("failed to serve the maintenance API: %v", err)
+			}
+		}
 		for i := 10; i >= 0; i-- {
 			log.Printf("rebooting in %d seconds\n", i)
 			time.Sleep(1 * time.Second)
@@ -0,0 +1,213 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package boot
+
+import (
+	"fmt"
+	"log"
+	"time"
+)
+
+// Task is a named step of the boot sequence. Undo reverts a task that
+// succeeded, and is run when the task is invalidated. It is required by the
+// tasks that cannot run twice, such as mounting a filesystem.
+type Task struct {
+	Name string
+	Func func() error
+	Undo func() error
+}
+
+// Phase is a named group of tasks that run in order.
+type Phase struct {
+	Name  string
+	Tasks []Task
+}
+
+// Error is the error returned by the sequencer when a task fails.
+type Error struct {
+	Phase string
+	Task  string
+	Err   error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("phase %q: task %q: %v", e.Phase, e.Task, e.Err)
+}
+
+// Sequencer runs the phases of the boot sequence, and records the start and
+// end times and the outcome of every task in a timeline. The timeline is
+// saved after every task, so that it survives the sequencer.
+//
+// The sequencer remembers the tasks that succeeded, so that the boot sequence
+// can be retried after a failure without running them again.
+type Sequencer struct {
+	path     string
+	timeline *Timeline
+	order    []string
+	done     map[string]map[string]bool
+	// undo holds the tasks that succeeded and can be undone, in the order
+	// in which they ran.
+	undo []undoTask
+}
+
+type undoTask struct {
+	phase string
+	task  Task
+}
+
+// NewSequencer initializes a Sequencer that saves its timeline at the path.
+// If a timeline was saved at the path by a previous sequencer, for example
+// before switching root, new phases are appended to it.
+func NewSequencer(path string) *Sequencer {
+	timeline, err := Load(path)
+	if err != nil {
+		timeline = &Timeline{}
+	}
+
+	return &Sequencer{
+		path:     path,
+		timeline: timeline,
+		done:     map[string]map[string]bool{},
+	}
+}
+
+// Timeline returns the timeline of the sequencer.
+func (s *Sequencer) Timeline() *Timeline {
+	return s.timeline
+}
+
+// Resume ends the task and the phase that were running when a previous
+// sequencer replaced itself, for example by switching root. They are
+// recorded as successful, since the new sequencer is running.
+func (s *Sequencer) Resume() {
+	now := time.Now()
+	for _, phase := range s.timeline.Phases {
+		for _, task := range phase.Tasks {
+			if task.End.IsZero() {
+				task.End = now
+			}
+		}
+		if phase.End.IsZero() {
+			phase.End = now
+		}
+	}
+
+	s.save()
+}
+
+// Run runs the phases in order. It returns an *Error for the first task that
+// fails, and no further tasks are run. Tasks that succeeded in a previous run
+// are skipped, and phases without tasks left to run are not recorded.
+func (s *Sequencer) Run(phases ...Phase) error {
+	for _, phase := range phases {
+		if _, ok := s.done[phase.Name]; !ok {
+			s.done[phase.Name] = map[string]bool{}
+			s.order = append(s.order, phase.Name)
+		}
+
+		var record *PhaseRecord
+		for _, task := range phase.Tasks {
+			if s.done[phase.Name][task.Name] {
+				continue
+			}
+			if record == nil {
+				record = &PhaseRecord{
+					Name:  phase.Name,
+					Start: time.Now(),
+					Tasks: []*TaskRecord{},
+				}
+				s.timeline.Phases = append(s.timeline.Phases, record)
+			}
+			if err := s.run(record, task); err != nil {
+				record.End = time.Now()
+				s.save()
+				return &Error{Phase: phase.Name, Task: task.Name, Err: err}
+			}
+			s.done[phase.Name][task.Name] = true
+			if task.Undo != nil {
+				s.undo = append(s.undo, undoTask{phase: phase.Name, task: task})
+			}
+		}
+
+		if record != nil {
+			record.End = time.Now()
+			s.save()
+		}
+	}
+
+	return nil
+}
+
+// Invalidate marks the tasks of the named phase, and of every phase that ran
+// after it, as not done, so that the next run runs them again. The invalidated
+// tasks that can be undone are undone first, last task first. Every task is
+// undone even if another fails, and the first error is returned.
+func (s *Sequencer) Invalidate(name string) (err error) {
+	invalidated := map[string]bool{}
+	for i, phase := range s.order {
+		if phase != name {
+			continue
+		}
+		for _, phase := range s.order[i:] {
+			invalidated[phase] = true
+		}
+		break
+	}
+
+	kept := []undoTask{}
+	for i := len(s.undo) - 1; i >= 0; i-- {
+		u := s.undo[i]
+		if !invalidated[u.phase] {
+			kept = append([]undoTask{u}, kept...)
+			continue
+		}
+		log.Printf("undoing boot task %q of phase %q", u.task.Name, u.phase)
+		if undoErr := u.task.Undo(); undoErr != nil && err == nil {
+			err = &Error{Phase: u.phase, Task: u.task.Name, Err: undoErr}
+		}
+	}
+	s.undo = kept
+
+	for phase := range invalidated {
+		s.done[phase] = map[string]bool{}
+	}
+
+	return err
+}
+
+func (s *Sequencer) run(phase *PhaseRecord, task Task) (err error) {
+	record := &TaskRecord{
+		Name:  task.Name,
+		Start: time.Now(),
+	}
+	phase.Tasks = append(phase.Tasks, record)
+	// The task may not return, for example if it switches root, so the
+	// timeline is saved before it runs.
+	s.save()
+
+	defer func() {
+		// A panicking task fails the boot sequence like any other failure.
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+
+		record.End = time.Now()
+		if err != nil {
+			record.Error = err.Error()
+		}
+		s.save()
+
+		log.Printf("boot task %q of phase %q took %s", task.Name, phase.Name, record.End.Sub(record.Start))
+	}()
+
+	return task.Func()
+}
+
+// save saves the timeline. Errors are ignored, since the timeline cannot be
+// saved until /run is mounted by the first tasks.
+func (s *Sequencer) save() {
+	// nolint: errcheck
+	s.timeline.Save(s.path)
+}
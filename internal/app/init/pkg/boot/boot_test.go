@@ -0,0 +1,225 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package boot
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSequencer_Run(t *testing.T) {
+	dir, err := ioutil.TempDir("", "boot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// nolint: errcheck
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "timeline.json")
+
+	ok := func() error { return nil }
+	ran := false
+
+	seq := NewSequencer(path)
+	err = seq.Run(
+		Phase{Name: "one", Tasks: []Task{{Name: "a", Func: ok}, {Name: "b", Func: ok}}},
+		Phase{Name: "two", Tasks: []Task{
+			{Name: "c", Func: func() error { return errors.New("failed") }},
+			{Name: "d", Func: func() error { ran = true; return nil }},
+		}},
+	)
+	if err == nil {
+		t.Fatal("Run() error = nil, want an error")
+	}
+	if ran {
+		t.Error("a task ran after a failed task")
+	}
+
+	timeline, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(timeline.Phases) != 2 || len(timeline.Phases[0].Tasks) != 2 || len(timeline.Phases[1].Tasks) != 1 {
+		t.Fatalf("unexpected timeline: %+v", timeline)
+	}
+	if task := timeline.Phases[1].Tasks[0]; task.Error != "failed" || task.End.IsZero() {
+		t.Errorf("unexpected failed task record: %+v", task)
+	}
+
+	// A new sequencer appends to the saved timeline.
+	seq = NewSequencer(path)
+	if err = seq.Run(Phase{Name: "three", Tasks: []Task{{Name: "e", Func: ok}}}); err != nil {
+		t.Fatal(err)
+	}
+	if len(seq.Timeline().Phases) != 3 {
+		t.Errorf("len(Phases) = %d, want 3", len(seq.Timeline().Phases))
+	}
+}
+
+func TestSequencer_Resume(t *testing.T) {
+	dir, err := ioutil.TempDir("", "boot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// nolint: errcheck
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "timeline.json")
+
+	// Simulate a task that does not return, like switching root.
+	seq := NewSequencer(path)
+	seq.timeline.Phases = []*PhaseRecord{{Name: "switch-root", Tasks: []*TaskRecord{{Name: "enter"}}}}
+	seq.save()
+
+	seq = NewSequencer(path)
+	seq.Resume()
+	phase := seq.Timeline().Phases[0]
+	if phase.End.IsZero() || phase.Tasks[0].End.IsZero() || phase.Tasks[0].Error != "" {
+		t.Errorf("unexpected resumed phase: %+v", phase)
+	}
+
+	b, err := seq.Timeline().ChromeTrace()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var trace struct {
+		TraceEvents []map[string]interface{} `json:"traceEvents"`
+	}
+	if err = json.Unmarshal(b, &trace); err != nil {
+		t.Fatal(err)
+	}
+	if len(trace.TraceEvents) != 2 {
+		t.Errorf("len(traceEvents) = %d, want 2", len(trace.TraceEvents))
+	}
+}
+
+func TestSequencer_Retry(t *testing.T) {
+	dir, err := ioutil.TempDir("", "boot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// nolint: errcheck
+	defer os.RemoveAll(dir)
+
+	runs := map[string]int{}
+	task := func(name string, fail *bool) Task {
+		return Task{Name: name, Func: func() error {
+			runs[name]++
+			if fail != nil && *fail {
+				panic("failed")
+			}
+			return nil
+		}}
+	}
+
+	fail := true
+	phases := []Phase{
+		{Name: "one", Tasks: []Task{task("a", nil)}},
+		{Name: "two", Tasks: []Task{task("b", nil), task("c", &fail)}},
+		{Name: "three", Tasks: []Task{task("d", nil)}},
+	}
+
+	seq := NewSequencer(filepath.Join(dir, "timeline.json"))
+	err = seq.Run(phases...)
+	if e, ok := err.(*Error); !ok || e.Phase != "two" || e.Task != "c" || e.Err.Error() != "panic: failed" {
+		t.Fatalf("Run() error = %v, want a panic of task c of phase two", err)
+	}
+
+	fail = false
+	if err = seq.Run(phases...); err != nil {
+		t.Fatal(err)
+	}
+	if runs["a"] != 1 || runs["b"] != 1 || runs["c"] != 2 || runs["d"] != 1 {
+		t.Errorf("unexpected runs after a retry: %v", runs)
+	}
+
+	if err = seq.Invalidate("two"); err != nil {
+		t.Fatal(err)
+	}
+	if err = seq.Run(phases...); err != nil {
+		t.Fatal(err)
+	}
+	if runs["a"] != 1 || runs["b"] != 2 || runs["c"] != 3 || runs["d"] != 2 {
+		t.Errorf("unexpected runs after an invalidation: %v", runs)
+	}
+	// one, two (failed), two (retried), three, two, three
+	if len(seq.Timeline().Phases) != 6 {
+		t.Errorf("len(Phases) = %d, want 6", len(seq.Timeline().Phases))
+	}
+}
+
+func TestSequencer_Undo(t *testing.T) {
+	dir, err := ioutil.TempDir("", "boot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// nolint: errcheck
+	defer os.RemoveAll(dir)
+
+	// The mount tasks fail when they run twice without being undone.
+	mounted := map[string]bool{}
+	undone := []string{}
+	mount := func(name string) Task {
+		return Task{
+			Name: name,
+			Func: func() error {
+				if mounted[name] {
+					return errors.New("already mounted")
+				}
+				mounted[name] = true
+				return nil
+			},
+			Undo: func() error {
+				mounted[name] = false
+				undone = append(undone, name)
+				return nil
+			},
+		}
+	}
+
+	fail := true
+	phases := []Phase{
+		{Name: "userdata", Tasks: []Task{{Name: "read", Func: func() error { return nil }}}},
+		{Name: "mount", Tasks: []Task{mount("boot"), mount("data")}},
+		{Name: "install", Tasks: []Task{{Name: "install", Func: func() error {
+			if fail {
+				return errors.New("failed")
+			}
+			return nil
+		}}}},
+	}
+
+	seq := NewSequencer(filepath.Join(dir, "timeline.json"))
+	if err = seq.Run(phases...); err == nil {
+		t.Fatal("Run() succeeded, want the install task to fail")
+	}
+
+	if err = seq.Invalidate("userdata"); err != nil {
+		t.Fatal(err)
+	}
+	if len(undone) != 2 || undone[0] != "data" || undone[1] != "boot" {
+		t.Errorf("undone = %v, want [data boot]", undone)
+	}
+
+	fail = false
+	if err = seq.Run(phases...); err != nil {
+		t.Fatalf("Run() after an invalidation error = %v", err)
+	}
+	if !mounted["boot"] || !mounted["data"] {
+		t.Errorf("mounted = %v, want every task mounted", mounted)
+	}
+
+	// Tasks that were already undone are not undone again, and the phases
+	// that ran before the invalidated phase are not undone.
+	undone = undone[:0]
+	if err = seq.Invalidate("install"); err != nil {
+		t.Fatal(err)
+	}
+	if len(undone) != 0 {
+		t.Errorf("undone = %v, want none", undone)
+	}
+}
@@ -0,0 +1,163 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package boot
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/autonomy/talos/internal/app/osd/proto"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/timestamp"
+)
+
+// Timeline is the record of the phases and tasks of the boot sequence.
+type Timeline struct {
+	Phases []*PhaseRecord `json:"phases"`
+}
+
+// PhaseRecord is the record of a phase. The end time is zero while the phase
+// is running.
+type PhaseRecord struct {
+	Name  string        `json:"name"`
+	Start time.Time     `json:"start"`
+	End   time.Time     `json:"end"`
+	Tasks []*TaskRecord `json:"tasks"`
+}
+
+// TaskRecord is the record of a task. The end time is zero while the task is
+// running, and the error is empty if the task succeeded.
+type TaskRecord struct {
+	Name  string    `json:"name"`
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+	Error string    `json:"error,omitempty"`
+}
+
+// Load reads a timeline from a file.
+func Load(path string) (*Timeline, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	timeline := &Timeline{}
+	if err = json.Unmarshal(b, timeline); err != nil {
+		return nil, err
+	}
+
+	return timeline, nil
+}
+
+// Save writes the timeline to a file. The file is replaced atomically, so
+// that readers never see a partial timeline.
+func (t *Timeline) Save(path string) error {
+	b, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	if err = ioutil.WriteFile(tmp, b, 0600); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, path)
+}
+
+// Proto converts the timeline to the reply of the BootTimeline RPC.
+func (t *Timeline) Proto() (reply *proto.BootTimelineReply, err error) {
+	reply = &proto.BootTimelineReply{}
+	for _, phase := range t.Phases {
+		p := &proto.BootPhase{Name: phase.Name}
+		if p.Start, p.End, err = timestamps(phase.Start, phase.End); err != nil {
+			return nil, err
+		}
+		for _, task := range phase.Tasks {
+			t := &proto.BootTask{Name: task.Name, Error: task.Error}
+			if t.Start, t.End, err = timestamps(task.Start, task.End); err != nil {
+				return nil, err
+			}
+			p.Tasks = append(p.Tasks, t)
+		}
+		reply.Phases = append(reply.Phases, p)
+	}
+
+	return reply, nil
+}
+
+// timestamps converts a start and an end time. A zero end time is left unset.
+func timestamps(start, end time.Time) (s, e *timestamp.Timestamp, err error) {
+	if s, err = ptypes.TimestampProto(start); err != nil {
+		return nil, nil, err
+	}
+	if end.IsZero() {
+		return s, nil, nil
+	}
+	if e, err = ptypes.TimestampProto(end); err != nil {
+		return nil, nil, err
+	}
+
+	return s, e, nil
+}
+
+// traceEvent is an event of the Chrome trace event format.
+type traceEvent struct {
+	Name      string            `json:"name"`
+	Category  string            `json:"cat"`
+	Phase     string            `json:"ph"`
+	Timestamp int64             `json:"ts"`
+	Duration  int64             `json:"dur"`
+	PID       int               `json:"pid"`
+	TID       int               `json:"tid"`
+	Args      map[string]string `json:"args,omitempty"`
+}
+
+// ChromeTrace returns the timeline in the Chrome trace event format, which
+// can be loaded in chrome://tracing. Every phase and task is a complete
+// event, and the tasks are nested under their phase. Events that have not
+// ended have a duration of zero.
+func (t *Timeline) ChromeTrace() ([]byte, error) {
+	events := []traceEvent{}
+
+	event := func(name, category string, start, end time.Time, args map[string]string) traceEvent {
+		e := traceEvent{
+			Name:      name,
+			Category:  category,
+			Phase:     "X",
+			Timestamp: start.UnixNano() / int64(time.Microsecond),
+			PID:       1,
+			TID:       1,
+			Args:      args,
+		}
+		if !end.IsZero() {
+			e.Duration = int64(end.Sub(start) / time.Microsecond)
+		}
+		return e
+	}
+
+	for _, phase := range t.Phases {
+		events = append(events, event(phase.Name, "phase", phase.Start, phase.End, nil))
+		for _, task := range phase.Tasks {
+			var args map[string]string
+			if task.Error != "" {
+				args = map[string]string{"error": task.Error}
+			}
+			events = append(events, event(task.Name, phase.Name, task.Start, task.End, args))
+		}
+	}
+
+	return json.Marshal(struct {
+		TraceEvents     []traceEvent `json:"traceEvents"`
+		DisplayTimeUnit string       `json:"displayTimeUnit"`
+	}{events, "ms"})
+}
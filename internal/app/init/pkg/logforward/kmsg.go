@@ -0,0 +1,42 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package logforward
+
+import (
+	"context"
+	"log"
+
+	"github.com/autonomy/talos/internal/pkg/kernel/kmsg"
+)
+
+// kmsg ships the records of the kernel log buffer, starting with the oldest
+// record still in the buffer.
+func (f *Forwarder) kmsg(ctx context.Context) {
+	records, err := kmsg.Read(ctx, true)
+	if err != nil {
+		log.Printf("failed to read the kernel log for forwarding: %v", err)
+		return
+	}
+
+	for record := range records {
+		f.publish(kmsgRecord(record))
+	}
+}
+
+func kmsgRecord(record *kmsg.Record) *Record {
+	r := &Record{
+		Timestamp: record.Timestamp,
+		Service:   "kernel",
+		Facility:  record.Facility,
+		Severity:  record.Priority,
+		Message:   record.Message,
+	}
+	if r.Facility != FacilityKern {
+		// Records written by user space, including the log of init.
+		r.Service = "kmsg"
+	}
+
+	return r
+}
@@ -0,0 +1,301 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package logforward
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	filechunker "github.com/autonomy/talos/internal/pkg/chunker/file"
+	"github.com/autonomy/talos/internal/pkg/userdata"
+	criconstants "github.com/containerd/cri/pkg/constants"
+)
+
+// maxLineLength is the length at which a line without a newline is shipped
+// as a record of its own.
+const maxLineLength = 64 * 1024
+
+// Forwarder ships the kernel log, the service logs, and the pod logs to the
+// configured sinks.
+type Forwarder struct {
+	options *Options
+	sinks   []*Sink
+
+	mu     sync.Mutex
+	tailed map[string]struct{}
+}
+
+// New initializes a Forwarder for the sinks. A sink that is invalid is
+// reported as an error.
+func New(sinks []*userdata.LogSink, setters ...Option) (*Forwarder, error) {
+	opts := NewDefaultOptions(setters...)
+
+	f := &Forwarder{
+		options: opts,
+		tailed:  map[string]struct{}{},
+	}
+
+	for _, config := range sinks {
+		sink, err := NewSink(config, opts.SpoolDir, opts.BufferSize)
+		if err != nil {
+			return nil, err
+		}
+		f.sinks = append(f.sinks, sink)
+	}
+
+	return f, nil
+}
+
+// Run ships logs until the context is canceled. Logs that appear in the log
+// directory are picked up every scan interval.
+func (f *Forwarder) Run(ctx context.Context) {
+	if len(f.sinks) == 0 {
+		return
+	}
+
+	for _, sink := range f.sinks {
+		go sink.Run(ctx)
+	}
+
+	go f.kmsg(ctx)
+
+	ticker := time.NewTicker(f.options.ScanInterval)
+	defer ticker.Stop()
+
+	for {
+		f.scan(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// publish pushes a record to every sink.
+func (f *Forwarder) publish(r *Record) {
+	if r.Hostname == "" {
+		// The hostname may be set after the forwarder is started.
+		// nolint: errcheck
+		r.Hostname, _ = os.Hostname()
+	}
+
+	for _, sink := range f.sinks {
+		if err := sink.Push(r); err != nil {
+			log.Printf("failed to buffer log record for sink %q: %v", sink.config.Endpoint, err)
+		}
+	}
+}
+
+// scan starts tailing the service logs and the pod logs that are not yet
+// tailed. Rotated generations are skipped.
+func (f *Forwarder) scan(ctx context.Context) {
+	f.scanDir(ctx, f.options.LogDir, func(path string) func([]byte, time.Time) {
+		return f.serviceLine(strings.TrimSuffix(filepath.Base(path), ".log"))
+	})
+	f.scanDir(ctx, f.options.PodLogDir, func(path string) func([]byte, time.Time) {
+		pod, container, ok := parsePodLogName(filepath.Base(path))
+		if !ok {
+			return nil
+		}
+		return f.podLine(pod, container)
+	})
+}
+
+// scanDir starts tailing the logs in the directory that are not yet tailed.
+// The handler of the lines of a log is returned by handler, and a log is
+// skipped if it returns nil.
+func (f *Forwarder) scanDir(ctx context.Context, dir string, handler func(path string) func([]byte, time.Time)) {
+	if dir == "" {
+		return
+	}
+
+	matches, err := filepath.Glob(filepath.Join(dir, "*.log"))
+	if err != nil {
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, path := range matches {
+		if _, ok := f.tailed[path]; ok {
+			continue
+		}
+		handle := handler(path)
+		if handle == nil {
+			continue
+		}
+		f.tailed[path] = struct{}{}
+		go f.tail(ctx, path, handle)
+	}
+}
+
+// tail passes the lines of a log to handle as they are written, along with
+// the time at which they were read. Tailing stops once the path is removed or
+// replaced, for example when a container exits or the kubelet rotates its
+// log, and the new file is picked up by the next scan.
+func (f *Forwarder) tail(ctx context.Context, path string, handle func([]byte, time.Time)) {
+	defer func() {
+		f.mu.Lock()
+		delete(f.tailed, path)
+		f.mu.Unlock()
+	}()
+
+	file, err := os.Open(path)
+	if err != nil {
+		log.Printf("failed to open log %s for forwarding: %v", path, err)
+		return
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		// nolint: errcheck
+		file.Close()
+		log.Printf("failed to stat log %s for forwarding: %v", path, err)
+		return
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	chunk := filechunker.NewChunker(
+		file,
+		filechunker.Follow(true),
+		filechunker.Until(f.replaced(ctx, path, info)),
+	)
+
+	var partial []byte
+	for data := range chunk.Read(ctx) {
+		read := time.Now()
+		partial = append(partial, data...)
+		for {
+			i := bytes.IndexByte(partial, '\n')
+			if i < 0 {
+				if len(partial) < maxLineLength {
+					break
+				}
+				i = len(partial)
+			}
+			handle(partial[:i], read)
+			if i == len(partial) {
+				partial = partial[:0]
+				break
+			}
+			partial = partial[i+1:]
+		}
+	}
+	if err = chunk.Err(); err != nil {
+		log.Printf("failed to read log %s for forwarding: %v", path, err)
+	}
+}
+
+// replaced returns a channel that is closed once the path no longer refers to
+// the file described by info.
+func (f *Forwarder) replaced(ctx context.Context, path string, info os.FileInfo) <-chan struct{} {
+	replacedC := make(chan struct{})
+
+	go func() {
+		ticker := time.NewTicker(f.options.ScanInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			if current, err := os.Stat(path); err != nil || !os.SameFile(info, current) {
+				close(replacedC)
+				return
+			}
+		}
+	}()
+
+	return replacedC
+}
+
+// serviceLine returns the handler of the lines of a service log. The
+// timestamp of a record is parsed from the line if it starts with one, and is
+// the time at which the line was read otherwise.
+func (f *Forwarder) serviceLine(id string) func([]byte, time.Time) {
+	return func(line []byte, read time.Time) {
+		timestamp, ok := filechunker.ParseTimestamp(line)
+		if !ok {
+			timestamp = read
+		}
+		f.publish(&Record{
+			Timestamp: timestamp,
+			Service:   id,
+			Namespace: f.options.Namespace(id),
+			Facility:  FacilityDaemon,
+			Severity:  SeverityInfo,
+			Message:   string(line),
+		})
+	}
+}
+
+// podLine returns the handler of the lines of a container log written by the
+// CRI plugin of containerd. Each line is formatted as
+// "<timestamp> <stream> <tag> <message>", where the tag is "P" for a partial
+// line that is continued by the next one, and "F" for a full line.
+func (f *Forwarder) podLine(pod, container string) func([]byte, time.Time) {
+	var message []byte
+
+	return func(line []byte, read time.Time) {
+		fields := bytes.SplitN(line, []byte(" "), 4)
+		if len(fields) != 4 {
+			fields = [][]byte{nil, []byte("stdout"), []byte("F"), line}
+		}
+		timestamp, err := time.Parse(time.RFC3339Nano, string(fields[0]))
+		if err != nil {
+			timestamp = read
+		}
+
+		message = append(message, fields[3]...)
+		if string(fields[2]) == "P" && len(message) < maxLineLength {
+			return
+		}
+
+		severity := SeverityInfo
+		if string(fields[1]) == "stderr" {
+			severity = SeverityErr
+		}
+		f.publish(&Record{
+			Timestamp: timestamp,
+			Service:   container,
+			Namespace: criconstants.K8sContainerdNamespace,
+			Pod:       pod,
+			Facility:  FacilityUser,
+			Severity:  severity,
+			Message:   string(message),
+		})
+		message = message[:0]
+	}
+}
+
+// parsePodLogName parses the name of a log in the pod log directory. The
+// kubelet names the logs "<pod>_<namespace>_<container>-<container ID>.log".
+// The pod is returned as "<namespace>/<pod>".
+func parsePodLogName(name string) (pod, container string, ok bool) {
+	parts := strings.Split(strings.TrimSuffix(name, ".log"), "_")
+	if len(parts) != 3 {
+		return "", "", false
+	}
+	i := strings.LastIndex(parts[2], "-")
+	if i <= 0 {
+		return "", "", false
+	}
+
+	return parts[1] + "/" + parts[0], parts[2][:i], true
+}
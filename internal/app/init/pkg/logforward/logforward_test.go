@@ -0,0 +1,158 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package logforward
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestSpool(t *testing.T) {
+	dir, err := ioutil.TempDir("", "logforward")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// nolint: errcheck
+	defer os.RemoveAll(dir)
+
+	s, err := NewSpool(dir, 8192)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if err = s.Push([]byte(fmt.Sprintf("record %d", i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	// An unacknowledged record is returned again.
+	for i, want := range []string{"record 0", "record 0", "record 1"} {
+		got, err := s.Peek(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != want {
+			t.Errorf("Peek() = %q, want %q", got, want)
+		}
+		if i == 1 {
+			s.Ack()
+		}
+	}
+	if err = s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Records survive a restart. Acknowledgements do not.
+	s, err = NewSpool(dir, 8192)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// nolint: errcheck
+	defer s.Close()
+	got, err := s.Peek(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "record 0" {
+		t.Errorf("Peek() after restart = %q, want %q", got, "record 0")
+	}
+}
+
+func TestSpool_Full(t *testing.T) {
+	dir, err := ioutil.TempDir("", "logforward")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// nolint: errcheck
+	defer os.RemoveAll(dir)
+
+	s, err := NewSpool(dir, 16*1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// nolint: errcheck
+	defer s.Close()
+
+	record := make([]byte, 1023)
+	for i := range record {
+		record[i] = 'a'
+	}
+	for i := 0; i < 64; i++ {
+		if err = s.Push(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var total int64
+	for _, seg := range s.segments {
+		total += seg.size
+	}
+	if total > 16*1024 {
+		t.Errorf("spool size = %d, want at most %d", total, 16*1024)
+	}
+}
+
+func TestRecord_Syslog(t *testing.T) {
+	r := &Record{
+		Timestamp: time.Date(2019, 3, 1, 10, 0, 0, 0, time.UTC),
+		Hostname:  "master-1",
+		Service:   "kubelet",
+		Namespace: "system",
+		Facility:  FacilityDaemon,
+		Severity:  SeverityInfo,
+		Message:   "started",
+	}
+	want := `<30>1 2019-03-01T10:00:00Z master-1 kubelet - - [talos@32473 namespace="system"] started`
+	if got := string(r.Syslog()); got != want {
+		t.Errorf("Syslog() = %q, want %q", got, want)
+	}
+}
+
+func TestRecord_SyslogPod(t *testing.T) {
+	r := &Record{
+		Timestamp: time.Date(2019, 3, 1, 10, 0, 0, 0, time.UTC),
+		Hostname:  "worker-1",
+		Service:   "nginx",
+		Namespace: "k8s.io",
+		Pod:       "default/nginx-7db9fccd9b-mzf7q",
+		Facility:  FacilityUser,
+		Severity:  SeverityErr,
+		Message:   "started",
+	}
+	want := `<11>1 2019-03-01T10:00:00Z worker-1 nginx - - [talos@32473 namespace="k8s.io" pod="default/nginx-7db9fccd9b-mzf7q"] started`
+	if got := string(r.Syslog()); got != want {
+		t.Errorf("Syslog() = %q, want %q", got, want)
+	}
+}
+
+// nolint: scopelint
+func TestParsePodLogName(t *testing.T) {
+	tests := []struct {
+		name      string
+		pod       string
+		container string
+		ok        bool
+	}{
+		{"coredns-86c58d9df4-4s9qt_kube-system_coredns-0a1b2c3d.log", "kube-system/coredns-86c58d9df4-4s9qt", "coredns", true},
+		{"web_default_nginx-proxy-0a1b2c3d.log", "default/web", "nginx-proxy", true},
+		{"kubelet.log", "", "", false},
+		{"web_default_nginx.log", "", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod, container, ok := parsePodLogName(tt.name)
+			if pod != tt.pod || container != tt.container || ok != tt.ok {
+				t.Errorf("parsePodLogName() = %q, %q, %v, want %q, %q, %v", pod, container, ok, tt.pod, tt.container, tt.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package logforward
+
+import (
+	"time"
+)
+
+// Options is the functional options struct.
+type Options struct {
+	// LogDir is the directory containing the service logs.
+	LogDir string
+	// PodLogDir is the directory containing the links to the logs of the
+	// Kubernetes containers.
+	PodLogDir string
+	// SpoolDir is the directory in which records are buffered for each sink.
+	SpoolDir string
+	// ScanInterval is the time between scans of LogDir for new logs.
+	ScanInterval time.Duration
+	// BufferSize is the default maximum size in bytes of the buffer of a
+	// sink.
+	BufferSize int64
+	// Namespace returns the containerd namespace of the service with the
+	// specified ID, or an empty string if the service runs on the host.
+	Namespace func(id string) string
+}
+
+// Option is the functional option func.
+type Option func(*Options)
+
+// NewDefaultOptions initializes a Options struct with default values.
+func NewDefaultOptions(setters ...Option) *Options {
+	opts := &Options{
+		LogDir:       "/var/log",
+		PodLogDir:    "/var/log/containers",
+		SpoolDir:     "/var/lib/logforward",
+		ScanInterval: 10 * time.Second,
+		BufferSize:   64 * 1024 * 1024,
+		Namespace:    func(string) string { return "" },
+	}
+
+	for _, setter := range setters {
+		setter(opts)
+	}
+
+	return opts
+}
+
+// WithLogDir sets the directory containing the service logs.
+func WithLogDir(o string) Option {
+	return func(args *Options) {
+		args.LogDir = o
+	}
+}
+
+// WithPodLogDir sets the directory containing the links to the pod logs.
+func WithPodLogDir(o string) Option {
+	return func(args *Options) {
+		args.PodLogDir = o
+	}
+}
+
+// WithSpoolDir sets the directory in which records are buffered.
+func WithSpoolDir(o string) Option {
+	return func(args *Options) {
+		args.SpoolDir = o
+	}
+}
+
+// WithScanInterval sets the time between scans for new logs.
+func WithScanInterval(o time.Duration) Option {
+	return func(args *Options) {
+		args.ScanInterval = o
+	}
+}
+
+// WithBufferSize sets the default maximum size of the buffer of a sink.
+func WithBufferSize(o int64) Option {
+	return func(args *Options) {
+		args.BufferSize = o
+	}
+}
+
+// WithNamespace sets the func used to look up the namespace of a service.
+func WithNamespace(o func(id string) string) Option {
+	return func(args *Options) {
+		args.Namespace = o
+	}
+}
@@ -0,0 +1,91 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package logforward
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Syslog facilities and severities used by the records.
+const (
+	FacilityKern   = 0
+	FacilityUser   = 1
+	FacilityDaemon = 3
+
+	SeverityErr  = 3
+	SeverityInfo = 6
+)
+
+// Record is a single line of a log. The pod is set for the lines of the
+// Kubernetes containers, as "<namespace>/<pod>".
+type Record struct {
+	Timestamp time.Time `json:"timestamp"`
+	Hostname  string    `json:"hostname"`
+	Service   string    `json:"service"`
+	Namespace string    `json:"namespace,omitempty"`
+	Pod       string    `json:"pod,omitempty"`
+	Facility  int       `json:"facility"`
+	Severity  int       `json:"severity"`
+	Message   string    `json:"message"`
+}
+
+// syslogEnterpriseID is the private enterprise number used for the
+// structured data of syslog messages.
+const syslogEnterpriseID = "32473"
+
+// Syslog formats the record as an RFC 5424 syslog message.
+func (r *Record) Syslog() []byte {
+	hostname := r.Hostname
+	if hostname == "" {
+		hostname = "-"
+	}
+	service := r.Service
+	if service == "" {
+		service = "-"
+	}
+
+	params := []string{}
+	if r.Namespace != "" {
+		params = append(params, fmt.Sprintf("namespace=\"%s\"", escapeParam(r.Namespace)))
+	}
+	if r.Pod != "" {
+		params = append(params, fmt.Sprintf("pod=\"%s\"", escapeParam(r.Pod)))
+	}
+	sd := "-"
+	if len(params) > 0 {
+		sd = fmt.Sprintf("[talos@%s %s]", syslogEnterpriseID, strings.Join(params, " "))
+	}
+
+	return []byte(fmt.Sprintf("<%d>1 %s %s %s - - %s %s",
+		r.Facility*8+r.Severity,
+		r.Timestamp.UTC().Format(time.RFC3339Nano),
+		truncate(hostname, 255),
+		truncate(service, 48),
+		sd,
+		r.Message,
+	))
+}
+
+// JSON formats the record as a JSON object.
+func (r *Record) JSON() ([]byte, error) {
+	return json.Marshal(r)
+}
+
+// escapeParam escapes the characters that must be escaped in the value of a
+// structured data parameter.
+func escapeParam(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`).Replace(s)
+}
+
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+
+	return s
+}
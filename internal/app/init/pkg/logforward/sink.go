@@ -0,0 +1,218 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package logforward
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/autonomy/talos/internal/pkg/userdata"
+)
+
+// Sink formats
+const (
+	FormatSyslog = "syslog"
+	FormatJSON   = "json"
+)
+
+const (
+	dialTimeout  = 10 * time.Second
+	writeTimeout = 10 * time.Second
+	minBackoff   = time.Second
+	maxBackoff   = time.Minute
+)
+
+// Sink ships records to a remote endpoint. Records are buffered in a spool
+// while the endpoint is unreachable.
+type Sink struct {
+	config    *userdata.LogSink
+	tlsConfig *tls.Config
+	spool     *Spool
+}
+
+// NewSink validates the configuration of a sink, and opens its spool in a
+// subdirectory of dir.
+func NewSink(config *userdata.LogSink, dir string, bufferSize int64) (*Sink, error) {
+	switch {
+	case config.Format != FormatSyslog && config.Format != FormatJSON:
+		return nil, fmt.Errorf("log sink %q: unknown format %q", config.Endpoint, config.Format)
+	case config.Protocol != "tcp" && config.Protocol != "udp":
+		return nil, fmt.Errorf("log sink %q: unknown protocol %q", config.Endpoint, config.Protocol)
+	case config.Protocol == "udp" && config.Format == FormatJSON:
+		return nil, fmt.Errorf("log sink %q: the json format requires tcp", config.Endpoint)
+	case config.Protocol == "udp" && config.TLS != nil:
+		return nil, fmt.Errorf("log sink %q: tls requires tcp", config.Endpoint)
+	}
+
+	if _, _, err := net.SplitHostPort(config.Endpoint); err != nil {
+		return nil, fmt.Errorf("log sink %q: %v", config.Endpoint, err)
+	}
+
+	s := &Sink{config: config}
+
+	if config.TLS != nil {
+		var err error
+		if s.tlsConfig, err = tlsConfig(config.TLS); err != nil {
+			return nil, fmt.Errorf("log sink %q: %v", config.Endpoint, err)
+		}
+	}
+
+	if config.BufferSize > 0 {
+		bufferSize = config.BufferSize
+	}
+
+	name := strings.NewReplacer(":", "_", "/", "_").Replace(
+		fmt.Sprintf("%s-%s-%s", config.Format, config.Protocol, config.Endpoint),
+	)
+
+	var err error
+	if s.spool, err = NewSpool(filepath.Join(dir, name), bufferSize); err != nil {
+		return nil, fmt.Errorf("log sink %q: %v", config.Endpoint, err)
+	}
+
+	return s, nil
+}
+
+// Push buffers a record to be shipped.
+func (s *Sink) Push(r *Record) error {
+	b, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	return s.spool.Push(b)
+}
+
+// Run ships the buffered records until the context is canceled. If the
+// endpoint cannot be reached, it is retried with an exponential backoff.
+func (s *Sink) Run(ctx context.Context) {
+	// nolint: errcheck
+	defer s.spool.Close()
+
+	var conn net.Conn
+	defer func() {
+		if conn != nil {
+			// nolint: errcheck
+			conn.Close()
+		}
+	}()
+
+	backoff := minBackoff
+	failing := false
+	fail := func(err error) bool {
+		if !failing {
+			log.Printf("log sink %q is unavailable, buffering records: %v", s.config.Endpoint, err)
+			failing = true
+		}
+		if conn != nil {
+			// nolint: errcheck
+			conn.Close()
+			conn = nil
+		}
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+		return true
+	}
+
+	for {
+		b, err := s.spool.Peek(ctx)
+		if err != nil {
+			return
+		}
+
+		var r Record
+		if err = json.Unmarshal(b, &r); err != nil {
+			// Drop corrupt records.
+			s.spool.Ack()
+			continue
+		}
+
+		if conn == nil {
+			if conn, err = s.dial(); err != nil {
+				if !fail(err) {
+					return
+				}
+				continue
+			}
+		}
+
+		if err = conn.SetWriteDeadline(time.Now().Add(writeTimeout)); err == nil {
+			_, err = conn.Write(s.encode(&r))
+		}
+		if err != nil {
+			if !fail(err) {
+				return
+			}
+			continue
+		}
+
+		s.spool.Ack()
+		if failing {
+			log.Printf("log sink %q is available", s.config.Endpoint)
+			failing = false
+		}
+		backoff = minBackoff
+	}
+}
+
+func (s *Sink) dial() (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: dialTimeout}
+	if s.tlsConfig != nil {
+		return tls.DialWithDialer(dialer, "tcp", s.config.Endpoint, s.tlsConfig)
+	}
+
+	return dialer.Dial(s.config.Protocol, s.config.Endpoint)
+}
+
+// encode formats a record for the wire. Syslog messages sent over TCP are
+// framed by octet counting, as described in RFC 6587.
+func (s *Sink) encode(r *Record) []byte {
+	if s.config.Format == FormatJSON {
+		// nolint: errcheck
+		b, _ := r.JSON()
+		return append(b, '\n')
+	}
+
+	msg := r.Syslog()
+	if s.config.Protocol == "udp" {
+		return msg
+	}
+
+	return append([]byte(fmt.Sprintf("%d ", len(msg))), msg...)
+}
+
+func tlsConfig(t *userdata.LogSinkTLS) (*tls.Config, error) {
+	config := &tls.Config{
+		ServerName:         t.ServerName,
+		InsecureSkipVerify: t.InsecureSkipVerify, // nolint: gosec
+	}
+
+	if t.CA != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM([]byte(t.CA)) {
+			return nil, fmt.Errorf("failed to parse the log sink CA")
+		}
+		config.RootCAs = pool
+	}
+
+	return config, nil
+}
@@ -0,0 +1,282 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package logforward
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const spoolExt = ".spool"
+
+// Spool is a bounded, on-disk FIFO of newline delimited records. Records are
+// appended to segment files, and a segment is removed once all of its records
+// have been acknowledged. Once the spool exceeds its maximum size, the oldest
+// segment is discarded.
+type Spool struct {
+	mu sync.Mutex
+
+	dir         string
+	maxSize     int64
+	segmentSize int64
+
+	// segments are ordered oldest first. The last segment is the one that
+	// records are appended to.
+	segments []*segment
+	head     *os.File
+
+	// reader reads the oldest segment, starting at offset.
+	reader  *os.File
+	offset  int64
+	pending int
+
+	notifyC chan struct{}
+}
+
+type segment struct {
+	seq  uint64
+	size int64
+}
+
+// NewSpool opens the spool in the directory, creating it if needed. Records
+// left in the directory by a previous spool are read first. Acknowledgements
+// are not persisted, so the records of a partially acknowledged segment are
+// returned again.
+func NewSpool(dir string, maxSize int64) (*Spool, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+
+	segmentSize := maxSize / 16
+	if segmentSize < 4096 {
+		segmentSize = 4096
+	}
+
+	s := &Spool{
+		dir:         dir,
+		maxSize:     maxSize,
+		segmentSize: segmentSize,
+		notifyC:     make(chan struct{}, 1),
+	}
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range files {
+		if !strings.HasSuffix(f.Name(), spoolExt) {
+			continue
+		}
+		seq, err := strconv.ParseUint(strings.TrimSuffix(f.Name(), spoolExt), 10, 64)
+		if err != nil {
+			continue
+		}
+		s.segments = append(s.segments, &segment{seq: seq, size: f.Size()})
+	}
+	sort.Slice(s.segments, func(i, j int) bool { return s.segments[i].seq < s.segments[j].seq })
+
+	if err = s.rotate(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Push appends a record to the spool. The record must not contain a newline.
+func (s *Spool) Push(record []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if last := s.segments[len(s.segments)-1]; last.size >= s.segmentSize {
+		if err := s.rotate(); err != nil {
+			return err
+		}
+	}
+
+	line := make([]byte, len(record)+1)
+	copy(line, record)
+	line[len(record)] = '\n'
+
+	n, err := s.head.Write(line)
+	s.segments[len(s.segments)-1].size += int64(n)
+	if err != nil {
+		return err
+	}
+
+	s.enforce()
+
+	select {
+	case s.notifyC <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
+
+// Peek returns the oldest record that has not been acknowledged, waiting for
+// one to be pushed if the spool is empty.
+func (s *Spool) Peek(ctx context.Context) ([]byte, error) {
+	for {
+		s.mu.Lock()
+		record, err := s.peek()
+		s.mu.Unlock()
+		if err != nil || record != nil {
+			return record, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-s.notifyC:
+		}
+	}
+}
+
+// Ack removes the record returned by the last call to Peek from the spool.
+func (s *Spool) Ack() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.offset += int64(s.pending)
+	s.pending = 0
+}
+
+// Close closes the files of the spool. The records that have not been
+// acknowledged are kept on disk.
+func (s *Spool) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.reader != nil {
+		// nolint: errcheck
+		s.reader.Close()
+		s.reader = nil
+	}
+
+	return s.head.Close()
+}
+
+// nolint: gocyclo
+func (s *Spool) peek() ([]byte, error) {
+	for {
+		oldest := s.segments[0]
+		isHead := len(s.segments) == 1
+
+		if s.offset >= oldest.size {
+			if isHead {
+				return nil, nil
+			}
+			if err := s.remove(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if s.reader == nil {
+			r, err := os.Open(s.path(oldest.seq))
+			if err != nil {
+				return nil, err
+			}
+			s.reader = r
+		}
+
+		buf := make([]byte, 4096)
+		var line []byte
+		for offset := s.offset; offset < oldest.size; {
+			n, err := s.reader.ReadAt(buf, offset)
+			if err != nil && err != io.EOF {
+				return nil, err
+			}
+			if i := bytes.IndexByte(buf[:n], '\n'); i >= 0 {
+				line = append(line, buf[:i]...)
+				s.pending = len(line) + 1
+				return line, nil
+			}
+			line = append(line, buf[:n]...)
+			offset += int64(n)
+			if n == 0 {
+				break
+			}
+		}
+
+		if isHead {
+			return nil, nil
+		}
+		// The segment ends with a partial record, left by an interrupted
+		// write. Skip it.
+		s.offset = oldest.size
+	}
+}
+
+// rotate starts a new segment.
+func (s *Spool) rotate() error {
+	var seq uint64
+	if len(s.segments) > 0 {
+		seq = s.segments[len(s.segments)-1].seq + 1
+	}
+
+	f, err := os.OpenFile(s.path(seq), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	if s.head != nil {
+		// nolint: errcheck
+		s.head.Close()
+	}
+	s.head = f
+	s.segments = append(s.segments, &segment{seq: seq})
+
+	return nil
+}
+
+// enforce discards the oldest segments until the spool fits in its maximum
+// size.
+func (s *Spool) enforce() {
+	var total int64
+	for _, seg := range s.segments {
+		total += seg.size
+	}
+
+	for total > s.maxSize && len(s.segments) > 1 {
+		size := s.segments[0].size
+		if err := s.remove(); err != nil {
+			log.Printf("failed to discard log spool segment: %v", err)
+			return
+		}
+		log.Printf("log spool %s is full, discarded %d bytes of records", s.dir, size)
+		total -= size
+	}
+}
+
+// remove removes the oldest segment.
+func (s *Spool) remove() error {
+	if s.reader != nil {
+		// nolint: errcheck
+		s.reader.Close()
+		s.reader = nil
+	}
+	if err := os.Remove(s.path(s.segments[0].seq)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	s.segments = s.segments[1:]
+	s.offset = 0
+	s.pending = 0
+
+	return nil
+}
+
+func (s *Spool) path(seq uint64) string {
+	return filepath.Join(s.dir, fmt.Sprintf("%020d%s", seq, spoolExt))
+}
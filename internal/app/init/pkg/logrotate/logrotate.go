@@ -0,0 +1,279 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package logrotate
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Rotator rotates the logs in a directory by size, and enforces the per
+// service quota and the global budget. The containerd shims hold the logs of
+// the containers open for writing, so a log is rotated by copying its
+// contents to a new generation and truncating it in place. Readers of a log
+// must therefore start over from the beginning when it shrinks.
+type Rotator struct {
+	options *Options
+}
+
+// New initializes and returns a Rotator.
+func New(setters ...Option) *Rotator {
+	return &Rotator{options: NewDefaultOptions(setters...)}
+}
+
+// Run rotates the logs at every interval until the context is canceled.
+func (r *Rotator) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.options.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := r.RotateAll(); err != nil {
+			log.Printf("failed to rotate logs: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RotateAll rotates the logs that exceed the maximum size, and then deletes
+// the oldest generations until the quota and the budget are met.
+func (r *Rotator) RotateAll() error {
+	logs, err := filepath.Glob(filepath.Join(r.options.Dir, "*.log"))
+	if err != nil {
+		return err
+	}
+
+	for _, p := range logs {
+		info, err := os.Stat(p)
+		if err != nil {
+			continue
+		}
+
+		if info.Size() >= r.options.MaxSize {
+			if err = r.Rotate(p); err != nil {
+				log.Printf("failed to rotate %s: %v", p, err)
+			}
+		}
+
+		if r.options.ServiceQuota > 0 {
+			if err = r.enforceQuota(p); err != nil {
+				log.Printf("failed to enforce the quota of %s: %v", p, err)
+			}
+		}
+	}
+
+	if r.options.Budget > 0 {
+		return r.enforceBudget(logs)
+	}
+
+	return nil
+}
+
+// Rotate shifts the generations of the log, discarding the oldest, copies
+// the log to the first generation, and truncates the log.
+func (r *Rotator) Rotate(p string) (err error) {
+	if r.options.Generations > 0 {
+		for i := r.options.Generations; i >= 1; i-- {
+			src, ok := generation(p, i)
+			if !ok {
+				continue
+			}
+			if i == r.options.Generations {
+				if err = os.Remove(src); err != nil {
+					return err
+				}
+				continue
+			}
+			dst := p + "." + strconv.Itoa(i+1) + strings.TrimPrefix(src, p+"."+strconv.Itoa(i))
+			if err = os.Rename(src, dst); err != nil {
+				return err
+			}
+		}
+
+		if err = r.copy(p, p+".1"); err != nil {
+			return err
+		}
+	}
+
+	return os.Truncate(p, 0)
+}
+
+// copy copies the log to the destination, compressing it if configured to.
+// The destination is written to a temporary file first, so that a partial
+// generation is never left behind.
+func (r *Rotator) copy(src, dst string) (err error) {
+	if r.options.Compress {
+		dst += ".gz"
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	// nolint: errcheck
+	defer in.Close()
+
+	tmp := dst + ".tmp"
+	out, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0640)
+	if err != nil {
+		return err
+	}
+	// nolint: errcheck
+	defer os.Remove(tmp)
+
+	var w io.WriteCloser = out
+	if r.options.Compress {
+		w = gzip.NewWriter(out)
+	}
+
+	if _, err = io.Copy(w, in); err != nil {
+		// nolint: errcheck
+		out.Close()
+		return err
+	}
+	if r.options.Compress {
+		if err = w.Close(); err != nil {
+			// nolint: errcheck
+			out.Close()
+			return err
+		}
+	}
+	if err = out.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, dst)
+}
+
+// enforceQuota deletes the oldest generations of the log until the log and
+// its generations fit in the quota.
+func (r *Rotator) enforceQuota(p string) error {
+	files := append([]string{p}, generations(p)...)
+
+	total := int64(0)
+	for _, f := range files {
+		total += size(f)
+	}
+
+	for i := len(files) - 1; i > 0 && total > r.options.ServiceQuota; i-- {
+		s := size(files[i])
+		if err := os.Remove(files[i]); err != nil {
+			return err
+		}
+		total -= s
+	}
+
+	if total > r.options.ServiceQuota {
+		return fmt.Errorf("%s exceeds the quota of %d bytes", p, r.options.ServiceQuota)
+	}
+
+	return nil
+}
+
+// enforceBudget deletes the oldest generations of all logs until the logs
+// fit in the budget.
+func (r *Rotator) enforceBudget(logs []string) error {
+	total := int64(0)
+	rotated := []string{}
+	for _, p := range logs {
+		total += size(p)
+		for _, g := range generations(p) {
+			total += size(g)
+			rotated = append(rotated, g)
+		}
+	}
+
+	sort.Slice(rotated, func(i, j int) bool { return modTime(rotated[i]).Before(modTime(rotated[j])) })
+
+	for _, g := range rotated {
+		if total <= r.options.Budget {
+			return nil
+		}
+		s := size(g)
+		if err := os.Remove(g); err != nil {
+			return err
+		}
+		total -= s
+	}
+
+	if total > r.options.Budget {
+		return fmt.Errorf("logs exceed the budget of %d bytes", r.options.Budget)
+	}
+
+	return nil
+}
+
+// generation returns the path of the specified generation of the log, if it
+// exists.
+func generation(p string, i int) (string, bool) {
+	for _, suffix := range []string{".gz", ""} {
+		g := p + "." + strconv.Itoa(i) + suffix
+		if _, err := os.Stat(g); err == nil {
+			return g, true
+		}
+	}
+
+	return "", false
+}
+
+// generations returns the paths of the existing generations of the log,
+// newest first.
+func generations(p string) []string {
+	matches, err := filepath.Glob(p + ".*")
+	if err != nil {
+		return nil
+	}
+
+	numbered := map[int]string{}
+	indexes := []int{}
+	for _, m := range matches {
+		suffix := strings.TrimSuffix(strings.TrimPrefix(m, p+"."), ".gz")
+		i, err := strconv.Atoi(suffix)
+		if err != nil {
+			continue
+		}
+		numbered[i] = m
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+
+	files := make([]string, len(indexes))
+	for j, i := range indexes {
+		files[j] = numbered[i]
+	}
+
+	return files
+}
+
+func size(p string) int64 {
+	info, err := os.Stat(p)
+	if err != nil {
+		return 0
+	}
+
+	return info.Size()
+}
+
+func modTime(p string) time.Time {
+	info, err := os.Stat(p)
+	if err != nil {
+		return time.Time{}
+	}
+
+	return info.ModTime()
+}
@@ -0,0 +1,59 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package logrotate
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestRotator_RotateAll(t *testing.T) {
+	dir, err := ioutil.TempDir("", "logrotate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// nolint: errcheck
+	defer os.RemoveAll(dir)
+
+	p := filepath.Join(dir, "osd.log")
+	r := New(WithDir(dir), WithMaxSize(10), WithGenerations(2), WithCompress(false), WithServiceQuota(0), WithBudget(0))
+
+	for _, contents := range []string{"first rotation", "second rotation", "third rotation", "small"} {
+		if err = ioutil.WriteFile(p, []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err = r.RotateAll(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := []string{p + ".1", p + ".2"}
+	if got := generations(p); !reflect.DeepEqual(got, want) {
+		t.Fatalf("generations() = %v, want %v", got, want)
+	}
+
+	for f, contents := range map[string]string{p: "small", p + ".1": "third rotation", p + ".2": "second rotation"} {
+		b, err := ioutil.ReadFile(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(b, []byte(contents)) {
+			t.Errorf("contents of %s = %q, want %q", f, b, contents)
+		}
+	}
+
+	// The quota leaves room for the log and one generation.
+	r = New(WithDir(dir), WithMaxSize(100), WithServiceQuota(25), WithBudget(0))
+	if err = r.RotateAll(); err != nil {
+		t.Fatal(err)
+	}
+	if got := generations(p); !reflect.DeepEqual(got, []string{p + ".1"}) {
+		t.Errorf("generations() = %v, want %v", got, []string{p + ".1"})
+	}
+}
@@ -0,0 +1,100 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package logrotate
+
+import (
+	"time"
+)
+
+// Options is the functional options struct.
+type Options struct {
+	// Dir is the directory containing the logs.
+	Dir string
+	// Interval is the time between checks of the log sizes.
+	Interval time.Duration
+	// MaxSize is the size in bytes at which a log is rotated.
+	MaxSize int64
+	// Generations is the number of rotated generations retained per log.
+	Generations int
+	// Compress enables gzip compression of the rotated generations.
+	Compress bool
+	// ServiceQuota is the maximum size in bytes of a log and its rotated
+	// generations. Zero means that there is no quota.
+	ServiceQuota int64
+	// Budget is the maximum size in bytes of all logs in the directory.
+	// Zero means that there is no budget.
+	Budget int64
+}
+
+// Option is the functional option func.
+type Option func(*Options)
+
+// NewDefaultOptions initializes a Options struct with default values.
+func NewDefaultOptions(setters ...Option) *Options {
+	opts := &Options{
+		Dir:          "/var/log",
+		Interval:     10 * time.Second,
+		MaxSize:      10 * 1024 * 1024,
+		Generations:  3,
+		Compress:     true,
+		ServiceQuota: 50 * 1024 * 1024,
+		Budget:       256 * 1024 * 1024,
+	}
+
+	for _, setter := range setters {
+		setter(opts)
+	}
+
+	return opts
+}
+
+// WithDir sets the directory containing the logs.
+func WithDir(o string) Option {
+	return func(args *Options) {
+		args.Dir = o
+	}
+}
+
+// WithInterval sets the time between checks of the log sizes.
+func WithInterval(o time.Duration) Option {
+	return func(args *Options) {
+		args.Interval = o
+	}
+}
+
+// WithMaxSize sets the size at which a log is rotated.
+func WithMaxSize(o int64) Option {
+	return func(args *Options) {
+		args.MaxSize = o
+	}
+}
+
+// WithGenerations sets the number of rotated generations retained per log.
+func WithGenerations(o int) Option {
+	return func(args *Options) {
+		args.Generations = o
+	}
+}
+
+// WithCompress enables gzip compression of the rotated generations.
+func WithCompress(o bool) Option {
+	return func(args *Options) {
+		args.Compress = o
+	}
+}
+
+// WithServiceQuota sets the maximum size of a log and its generations.
+func WithServiceQuota(o int64) Option {
+	return func(args *Options) {
+		args.ServiceQuota = o
+	}
+}
+
+// WithBudget sets the maximum size of all logs in the directory.
+func WithBudget(o int64) Option {
+	return func(args *Options) {
+		args.Budget = o
+	}
+}
@@ -5,75 +5,152 @@
 package conditions
 
 import (
-	"os"
+	"context"
+	"fmt"
+	"strings"
 	"time"
 )
 
-// ConditionFunc is the signature that all condition funcs must have.
-type ConditionFunc = func() (bool, error)
+// PollInterval is the interval at which the polling conditions check
+// whether they are met.
+const PollInterval = time.Second
+
+// ConditionFunc is the signature that all condition funcs must have. It
+// returns true if the condition is met, and false if it can never be met. It
+// must return the context's error once the context is canceled.
+type ConditionFunc = func(context.Context) (bool, error)
+
+// Condition is a service condition.
+type Condition interface {
+	// Wait blocks until the condition is met, until it can never be met, or
+	// until the context is canceled.
+	Wait(context.Context) (bool, error)
+	// String describes what the condition waits for. It completes the
+	// sentence "waiting for ...".
+	String() string
+}
+
+type condition struct {
+	description string
+	fn          ConditionFunc
+}
+
+func (c *condition) Wait(ctx context.Context) (bool, error) {
+	return c.fn(ctx)
+}
+
+func (c *condition) String() string {
+	return c.description
+}
+
+// New returns a condition that invokes the condition func, and is described
+// by the description.
+func New(description string, fn ConditionFunc) Condition {
+	return &condition{description: description, fn: fn}
+}
 
 // None is a service condition that has no conditions.
-func None() ConditionFunc {
-	return func() (bool, error) {
+func None() Condition {
+	return New("nothing", func(context.Context) (bool, error) {
 		return true, nil
-	}
+	})
 }
 
-// FileExists is a service condition that checks for the existence of a file
-// once and only once.
-func FileExists(file string) ConditionFunc {
-	return func() (bool, error) {
-		_, err := os.Stat(file)
-		if err != nil {
-			if os.IsNotExist(err) {
-				return false, nil
+// And is a service condition that is met once all of the conditions are met.
+// The conditions are waited for in order.
+func And(conditions ...Condition) Condition {
+	return New(describe(" and ", conditions), func(ctx context.Context) (bool, error) {
+		for _, c := range conditions {
+			ok, err := c.Wait(ctx)
+			if err != nil || !ok {
+				return ok, err
 			}
-
-			return false, err
 		}
 
 		return true, nil
-	}
+	})
 }
 
-// WaitForFileToExist is a service condition that will wait for the existence of
-// a file.
-func WaitForFileToExist(file string) ConditionFunc {
-	return func() (bool, error) {
-		for {
-			exists, err := FileExists(file)()
-			if err != nil {
-				return false, err
-			}
+// Or is a service condition that is met once any of the conditions is met.
+// The conditions are waited for concurrently. An error is returned only if
+// none of the conditions is met and at least one of them failed.
+func Or(conditions ...Condition) Condition {
+	return New(describe(" or ", conditions), func(ctx context.Context) (bool, error) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		type result struct {
+			ok  bool
+			err error
+		}
+
+		results := make(chan result, len(conditions))
+		for _, c := range conditions {
+			go func(c Condition) {
+				ok, err := c.Wait(ctx)
+				results <- result{ok, err}
+			}(c)
+		}
 
-			if exists {
+		var err error
+		for range conditions {
+			r := <-results
+			if r.ok {
 				return true, nil
 			}
-			time.Sleep(1 * time.Second)
+			if r.err != nil && err == nil {
+				err = r.err
+			}
 		}
-	}
+
+		return false, err
+	})
 }
 
-// WaitForFilesToExist is a service condition that will wait for the existence a
-// set of files.
-func WaitForFilesToExist(files ...string) ConditionFunc {
-	return func() (exists bool, err error) {
-	L:
-		for {
-			for _, f := range files {
-				exists, err = FileExists(f)()
-				if err != nil {
-					return false, err
-				}
-				if !exists {
-					time.Sleep(1 * time.Second)
-					continue L
-				}
-			}
-			if exists {
-				return true, nil
-			}
-			time.Sleep(1 * time.Second)
+// WithTimeout is a service condition that fails if the condition is not met
+// within the timeout.
+func WithTimeout(c Condition, timeout time.Duration) Condition {
+	return New(c.String(), func(ctx context.Context) (bool, error) {
+		timeoutCtx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		ok, err := c.Wait(timeoutCtx)
+		if err != nil && ctx.Err() == nil && timeoutCtx.Err() == context.DeadlineExceeded {
+			return false, fmt.Errorf("timed out after %s waiting for %s", timeout, c)
+		}
+
+		return ok, err
+	})
+}
+
+// poll invokes check every PollInterval until it returns true, returns an
+// error, or until the context is canceled.
+func poll(ctx context.Context, check func() (bool, error)) (bool, error) {
+	ticker := time.NewTicker(PollInterval)
+	defer ticker.Stop()
+
+	for {
+		ok, err := check()
+		if err != nil {
+			return false, err
+		}
+		if ok {
+			return true, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return false, ctx.Err()
+		case <-ticker.C:
 		}
 	}
 }
+
+func describe(sep string, conditions []Condition) string {
+	descriptions := make([]string, len(conditions))
+	for i, c := range conditions {
+		descriptions[i] = c.String()
+	}
+
+	return strings.Join(descriptions, sep)
+}
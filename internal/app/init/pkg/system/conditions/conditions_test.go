@@ -0,0 +1,82 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package conditions
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func constant(description string, ok bool, err error) Condition {
+	return New(description, func(context.Context) (bool, error) {
+		return ok, err
+	})
+}
+
+func blocking(description string) Condition {
+	return New(description, func(ctx context.Context) (bool, error) {
+		<-ctx.Done()
+		return false, ctx.Err()
+	})
+}
+
+// nolint: scopelint
+func Test_Wait(t *testing.T) {
+	errFailed := errors.New("failed")
+
+	tests := []struct {
+		name            string
+		condition       Condition
+		wantOK          bool
+		wantErr         bool
+		wantDescription string
+	}{
+		{
+			name:            "and",
+			condition:       And(constant("a", true, nil), constant("b", true, nil)),
+			wantOK:          true,
+			wantDescription: "a and b",
+		},
+		{
+			name:            "and not met",
+			condition:       And(constant("a", true, nil), constant("b", false, nil), blocking("c")),
+			wantDescription: "a and b and c",
+		},
+		{
+			name:            "or",
+			condition:       Or(blocking("a"), constant("b", true, nil)),
+			wantOK:          true,
+			wantDescription: "a or b",
+		},
+		{
+			name:            "or failed",
+			condition:       Or(constant("a", false, nil), constant("b", false, errFailed)),
+			wantErr:         true,
+			wantDescription: "a or b",
+		},
+		{
+			name:            "timeout",
+			condition:       WithTimeout(blocking("a"), time.Millisecond),
+			wantErr:         true,
+			wantDescription: "a",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, err := tt.condition.Wait(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Wait() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if ok != tt.wantOK {
+				t.Errorf("Wait() = %v, want %v", ok, tt.wantOK)
+			}
+			if tt.condition.String() != tt.wantDescription {
+				t.Errorf("String() = %q, want %q", tt.condition.String(), tt.wantDescription)
+			}
+		})
+	}
+}
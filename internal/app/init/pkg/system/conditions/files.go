@@ -0,0 +1,74 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package conditions
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"regexp"
+)
+
+// FileExists is a service condition that checks for the existence of a file
+// once and only once.
+func FileExists(file string) Condition {
+	return New(fmt.Sprintf("file %q to exist", file), func(context.Context) (bool, error) {
+		return fileExists(file)
+	})
+}
+
+// WaitForFileToExist is a service condition that will wait for the existence of
+// a file.
+func WaitForFileToExist(file string) Condition {
+	return New(fmt.Sprintf("file %q to exist", file), func(ctx context.Context) (bool, error) {
+		return poll(ctx, func() (bool, error) {
+			return fileExists(file)
+		})
+	})
+}
+
+// WaitForFilesToExist is a service condition that will wait for the existence a
+// set of files.
+func WaitForFilesToExist(files ...string) Condition {
+	conditions := make([]Condition, len(files))
+	for i, file := range files {
+		conditions[i] = WaitForFileToExist(file)
+	}
+
+	return And(conditions...)
+}
+
+// WaitForFileContents is a service condition that will wait for the contents
+// of a file to match the regular expression.
+func WaitForFileContents(file string, re *regexp.Regexp) Condition {
+	return New(fmt.Sprintf("contents of file %q to match %q", file, re), func(ctx context.Context) (bool, error) {
+		return poll(ctx, func() (bool, error) {
+			contents, err := ioutil.ReadFile(file)
+			if err != nil {
+				if os.IsNotExist(err) {
+					return false, nil
+				}
+
+				return false, err
+			}
+
+			return re.Match(contents), nil
+		})
+	})
+}
+
+func fileExists(file string) (bool, error) {
+	_, err := os.Stat(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	return true, nil
+}
@@ -0,0 +1,47 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package conditions
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/containerd/containerd"
+	"github.com/containerd/containerd/defaults"
+	"github.com/containerd/containerd/errdefs"
+	"github.com/containerd/containerd/namespaces"
+)
+
+// WaitForContainerdImage is a service condition that will wait for an image
+// to exist in the containerd namespace.
+func WaitForContainerdImage(namespace, ref string) Condition {
+	return New(fmt.Sprintf("image %q in namespace %q", ref, namespace), func(ctx context.Context) (bool, error) {
+		if ok, err := WaitForFileToExist(defaults.DefaultAddress).Wait(ctx); !ok || err != nil {
+			return ok, err
+		}
+
+		client, err := containerd.New(defaults.DefaultAddress)
+		if err != nil {
+			return false, err
+		}
+		// nolint: errcheck
+		defer client.Close()
+
+		ctx = namespaces.WithNamespace(ctx, namespace)
+
+		return poll(ctx, func() (bool, error) {
+			_, err := client.ImageService().Get(ctx, ref)
+			if err != nil {
+				if errdefs.IsNotFound(err) {
+					return false, nil
+				}
+
+				return false, err
+			}
+
+			return true, nil
+		})
+	})
+}
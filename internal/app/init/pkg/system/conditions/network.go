@@ -0,0 +1,70 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package conditions
+
+import (
+	"context"
+	"fmt"
+	"net"
+)
+
+// WaitForNetworkAddress is a service condition that will wait for a global
+// unicast IP address to be assigned to the interface. If the interface is
+// empty, an address on any interface satisfies the condition.
+func WaitForNetworkAddress(iface string) Condition {
+	description := "an IP address to be assigned"
+	if iface != "" {
+		description = fmt.Sprintf("an IP address to be assigned to %q", iface)
+	}
+
+	return New(description, func(ctx context.Context) (bool, error) {
+		return poll(ctx, func() (bool, error) {
+			return addressAssigned(iface)
+		})
+	})
+}
+
+// WaitForTCPPort is a service condition that will wait for a TCP port to
+// accept connections.
+func WaitForTCPPort(address string) Condition {
+	return New(fmt.Sprintf("%s to accept TCP connections", address), func(ctx context.Context) (bool, error) {
+		return poll(ctx, func() (bool, error) {
+			conn, err := net.DialTimeout("tcp", address, PollInterval)
+			if err != nil {
+				// Connection failures mean that the port is not yet
+				// listening.
+				return false, nil
+			}
+
+			return true, conn.Close()
+		})
+	})
+}
+
+func addressAssigned(name string) (bool, error) {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return false, err
+	}
+
+	for _, iface := range ifaces {
+		if name != "" && iface.Name != name {
+			continue
+		}
+
+		addrs, err := iface.Addrs()
+		if err != nil {
+			return false, err
+		}
+
+		for _, addr := range addrs {
+			if ipnet, ok := addr.(*net.IPNet); ok && ipnet.IP.IsGlobalUnicast() {
+				return true, nil
+			}
+		}
+	}
+
+	return false, nil
+}
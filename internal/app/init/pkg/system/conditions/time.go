@@ -0,0 +1,36 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package conditions
+
+import (
+	"context"
+
+	"golang.org/x/sys/unix"
+)
+
+// These are defined in linux/timex.h.
+const (
+	timeError = 5
+	staUnsync = 0x0040
+)
+
+// WaitForTimeSync is a service condition that will wait for the kernel to
+// report that the system clock is synchronized.
+func WaitForTimeSync() Condition {
+	return New("the time to be synchronized", func(ctx context.Context) (bool, error) {
+		return poll(ctx, timeSynchronized)
+	})
+}
+
+func timeSynchronized() (bool, error) {
+	var timex unix.Timex
+
+	state, err := unix.Adjtimex(&timex)
+	if err != nil {
+		return false, err
+	}
+
+	return state != timeError && timex.Status&staUnsync == 0, nil
+}
@@ -0,0 +1,69 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package system
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	unvisited = iota
+	visiting
+	visited
+)
+
+// dependencyOrder returns the specified IDs sorted such that every service
+// appears after the services it depends on. The graph maps the ID of every
+// known service to the IDs of its dependencies. Dependencies that are not in
+// the graph are assumed to be satisfied at a later time. An error is returned
+// if the graph contains a cycle reachable from any of the IDs.
+func dependencyOrder(graph map[string][]string, ids []string) (order []string, err error) {
+	requested := map[string]bool{}
+	for _, id := range ids {
+		requested[id] = true
+	}
+
+	state := map[string]int{}
+	path := []string{}
+
+	var visit func(id string) error
+	visit = func(id string) error {
+		switch state[id] {
+		case visited:
+			return nil
+		case visiting:
+			for i := range path {
+				if path[i] == id {
+					return fmt.Errorf("dependency cycle: %s", strings.Join(append(path[i:], id), " -> "))
+				}
+			}
+		}
+
+		state[id] = visiting
+		path = append(path, id)
+		for _, dependency := range graph[id] {
+			if err := visit(dependency); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[id] = visited
+
+		if requested[id] {
+			order = append(order, id)
+		}
+
+		return nil
+	}
+
+	for _, id := range ids {
+		if err = visit(id); err != nil {
+			return nil, err
+		}
+	}
+
+	return order, nil
+}
@@ -0,0 +1,88 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+// nolint: scopelint
+package system
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_dependencyOrder(t *testing.T) {
+	type args struct {
+		graph map[string][]string
+		ids   []string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "no dependencies",
+			args: args{
+				graph: map[string][]string{"udevd": nil, "containerd": nil},
+				ids:   []string{"udevd", "containerd"},
+			},
+			want: []string{"udevd", "containerd"},
+		},
+		{
+			name: "dependencies before dependents",
+			args: args{
+				graph: map[string][]string{
+					"kubeadm":    {"containerd", "trustd"},
+					"trustd":     {"containerd"},
+					"containerd": nil,
+				},
+				ids: []string{"kubeadm", "trustd", "containerd"},
+			},
+			want: []string{"containerd", "trustd", "kubeadm"},
+		},
+		{
+			name: "previously registered dependency",
+			args: args{
+				graph: map[string][]string{
+					"osd":        {"containerd"},
+					"containerd": nil,
+				},
+				ids: []string{"osd"},
+			},
+			want: []string{"osd"},
+		},
+		{
+			name: "unknown dependency",
+			args: args{
+				graph: map[string][]string{"proxyd": {"network"}},
+				ids:   []string{"proxyd"},
+			},
+			want: []string{"proxyd"},
+		},
+		{
+			name: "cycle",
+			args: args{
+				graph: map[string][]string{
+					"a": {"b"},
+					"b": {"c"},
+					"c": {"a"},
+				},
+				ids: []string{"a", "b", "c"},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := dependencyOrder(tt.args.graph, tt.args.ids)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("dependencyOrder() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("dependencyOrder() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
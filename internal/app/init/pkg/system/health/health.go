@@ -0,0 +1,74 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package health
+
+import (
+	"context"
+	"time"
+)
+
+// Check is the signature that all health checks must have. A nil error
+// indicates that the service is healthy.
+type Check = func(context.Context) error
+
+// Settings configures how a health check is run.
+type Settings struct {
+	// InitialDelay is the time to wait before the first check.
+	InitialDelay time.Duration
+	// Period is the time between checks.
+	Period time.Duration
+	// Timeout is the time after which a check is considered failed.
+	Timeout time.Duration
+}
+
+// DefaultSettings are the health check settings used when a service does not
+// specify its own.
+var DefaultSettings = Settings{
+	InitialDelay: time.Second,
+	Period:       5 * time.Second,
+	Timeout:      2 * time.Second,
+}
+
+// Run runs the health check periodically, and records the results in the
+// state until the context is canceled.
+func Run(ctx context.Context, settings *Settings, state *State, check Check) {
+	state.Init()
+
+	select {
+	case <-ctx.Done():
+		return
+	case <-time.After(settings.InitialDelay):
+	}
+
+	ticker := time.NewTicker(settings.Period)
+	defer ticker.Stop()
+
+	for {
+		checkCtx, cancel := context.WithTimeout(ctx, settings.Timeout)
+		err := check(checkCtx)
+		cancel()
+
+		// Do not record the failure of a check that was interrupted.
+		if ctx.Err() != nil {
+			return
+		}
+
+		state.Update(err == nil, errorMessage(err))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func errorMessage(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	return err.Error()
+}
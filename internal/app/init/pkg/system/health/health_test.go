@@ -0,0 +1,69 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package health
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+func Test_Run(t *testing.T) {
+	settings := &Settings{Period: time.Millisecond, Timeout: time.Second}
+
+	var state State
+	changes := make(chan StateChange, 2)
+	state.Subscribe(changes)
+
+	calls := 0
+	check := func(context.Context) error {
+		calls++
+		if calls < 3 {
+			return errors.New("not yet")
+		}
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go Run(ctx, settings, &state, check)
+
+	for _, want := range []bool{false, true} {
+		select {
+		case change := <-changes:
+			if *change.New.Healthy != want {
+				t.Fatalf("expected healthy = %v, got %v", want, *change.New.Healthy)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for a state change")
+		}
+	}
+
+	if status := state.Get(); status.LastMessage != "" {
+		t.Errorf("expected an empty message, got %q", status.LastMessage)
+	}
+}
+
+func Test_TCP(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := l.Addr().String()
+
+	if err = TCP(address)(context.Background()); err != nil {
+		t.Errorf("expected the check to succeed: %v", err)
+	}
+
+	if err = l.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = TCP(address)(context.Background()); err == nil {
+		t.Error("expected the check to fail")
+	}
+}
@@ -0,0 +1,156 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package health
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/containerd/containerd"
+	"github.com/containerd/containerd/cio"
+	"github.com/containerd/containerd/defaults"
+	"github.com/containerd/containerd/namespaces"
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// TCP is a health check that succeeds if a TCP connection to the address can
+// be established.
+func TCP(address string) Check {
+	return func(ctx context.Context) error {
+		var d net.Dialer
+		conn, err := d.DialContext(ctx, "tcp", address)
+		if err != nil {
+			return err
+		}
+
+		return conn.Close()
+	}
+}
+
+// HTTP is a health check that succeeds if a GET request to the URL returns a
+// 2xx or 3xx status code. The TLS config is optional, and is used for https
+// URLs.
+func HTTP(url string, config *tls.Config) Check {
+	return func(ctx context.Context) error {
+		req, err := http.NewRequest(http.MethodGet, url, nil)
+		if err != nil {
+			return err
+		}
+
+		client := &http.Client{
+			Transport: &http.Transport{TLSClientConfig: config},
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		}
+
+		resp, err := client.Do(req.WithContext(ctx))
+		if err != nil {
+			return err
+		}
+		// nolint: errcheck
+		defer resp.Body.Close()
+
+		if resp.StatusCode < 200 || resp.StatusCode >= 400 {
+			return fmt.Errorf("GET %s: unexpected status: %s", url, resp.Status)
+		}
+
+		return nil
+	}
+}
+
+// GRPC is a health check that succeeds if the server at the address reports
+// the service as serving according to the gRPC health checking protocol. An
+// empty service name checks the health of the server as a whole.
+func GRPC(address, service string, opts ...grpc.DialOption) Check {
+	return func(ctx context.Context) error {
+		conn, err := grpc.DialContext(ctx, address, opts...)
+		if err != nil {
+			return err
+		}
+		// nolint: errcheck
+		defer conn.Close()
+
+		resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{Service: service})
+		if err != nil {
+			return err
+		}
+
+		if resp.Status != healthpb.HealthCheckResponse_SERVING {
+			return fmt.Errorf("service %q is %s", service, resp.Status)
+		}
+
+		return nil
+	}
+}
+
+// Exec is a health check that succeeds if the command exits with a zero exit
+// code when run inside the running task of a containerd container.
+func Exec(namespace, id string, args ...string) Check {
+	return func(ctx context.Context) error {
+		client, err := containerd.New(defaults.DefaultAddress)
+		if err != nil {
+			return err
+		}
+		// nolint: errcheck
+		defer client.Close()
+
+		ctx = namespaces.WithNamespace(ctx, namespace)
+
+		container, err := client.LoadContainer(ctx, id)
+		if err != nil {
+			return err
+		}
+		spec, err := container.Spec(ctx)
+		if err != nil {
+			return err
+		}
+		task, err := container.Task(ctx, nil)
+		if err != nil {
+			return err
+		}
+
+		pspec := *spec.Process
+		pspec.Args = args
+		pspec.Terminal = false
+
+		execID := fmt.Sprintf("health-%d", time.Now().UnixNano())
+		process, err := task.Exec(ctx, execID, &pspec, cio.NullIO)
+		if err != nil {
+			return err
+		}
+		// The process must be cleaned up even if the check timed out.
+		// nolint: errcheck
+		defer process.Delete(namespaces.WithNamespace(context.Background(), namespace), containerd.WithProcessKill)
+
+		statusC, err := process.Wait(ctx)
+		if err != nil {
+			return err
+		}
+		if err = process.Start(ctx); err != nil {
+			return err
+		}
+
+		select {
+		case status := <-statusC:
+			code, _, err := status.Result()
+			if err != nil {
+				return err
+			}
+			if code != 0 {
+				return fmt.Errorf("%v exited with code %d", args, code)
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		return nil
+	}
+}
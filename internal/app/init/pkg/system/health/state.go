@@ -0,0 +1,105 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package health
+
+import (
+	"sync"
+	"time"
+)
+
+// Status is the result of the most recent health checks of a service.
+type Status struct {
+	// Healthy is nil until the first check has completed.
+	Healthy *bool
+	// LastMessage is the message of the last failed check, and is empty if
+	// the last check succeeded.
+	LastMessage string
+	// LastChange is the time at which the health of the service last
+	// changed.
+	LastChange time.Time
+}
+
+// StateChange describes a change in the health of a service.
+type StateChange struct {
+	Old Status
+	New Status
+}
+
+// State tracks the health of a service, and notifies subscribers whenever
+// the health changes.
+type State struct {
+	mu sync.Mutex
+
+	status      Status
+	subscribers []chan<- StateChange
+}
+
+// Init resets the state to unknown.
+func (state *State) Init() {
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	state.status = Status{LastChange: time.Now()}
+}
+
+// Update records the result of a health check. Subscribers are notified if
+// the health of the service changed.
+func (state *State) Update(healthy bool, message string) {
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	old := state.status
+
+	if old.Healthy != nil && *old.Healthy == healthy {
+		state.status.LastMessage = message
+		return
+	}
+
+	state.status = Status{
+		Healthy:     &healthy,
+		LastMessage: message,
+		LastChange:  time.Now(),
+	}
+
+	change := StateChange{Old: old, New: state.status}
+	for _, ch := range state.subscribers {
+		select {
+		case ch <- change:
+		default:
+			// Drop the notification rather than block the health check.
+		}
+	}
+}
+
+// Get returns the current status.
+func (state *State) Get() Status {
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	return state.status
+}
+
+// Subscribe registers a channel to be notified of changes in health. A
+// notification is dropped if the channel is full, so the subscribers should
+// read the current status with Get once they are notified.
+func (state *State) Subscribe(ch chan<- StateChange) {
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	state.subscribers = append(state.subscribers, ch)
+}
+
+// Unsubscribe removes a channel registered with Subscribe.
+func (state *State) Unsubscribe(ch chan<- StateChange) {
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	for i := range state.subscribers {
+		if state.subscribers[i] == ch {
+			state.subscribers = append(state.subscribers[:i], state.subscribers[i+1:]...)
+			return
+		}
+	}
+}
@@ -7,9 +7,14 @@ package containerd
 import (
 	"context"
 	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
 
 	"github.com/autonomy/talos/internal/app/init/pkg/system/conditions"
 	"github.com/autonomy/talos/internal/app/init/pkg/system/runner"
+	filechunker "github.com/autonomy/talos/internal/pkg/chunker/file"
 	"github.com/autonomy/talos/internal/pkg/userdata"
 	"github.com/containerd/containerd"
 	"github.com/containerd/containerd/cio"
@@ -17,12 +22,20 @@ import (
 	"github.com/containerd/containerd/defaults"
 	"github.com/containerd/containerd/namespaces"
 	"github.com/containerd/containerd/oci"
-	"github.com/containerd/containerd/runtime/restart"
 	specs "github.com/opencontainers/runtime-spec/specs-go"
+	"golang.org/x/sys/unix"
 )
 
 // Containerd represents a service to be run in a container.
-type Containerd struct{}
+type Containerd struct {
+	runner.Restarter
+
+	mu sync.Mutex
+	// stopC is closed to stop the task.
+	stopC chan struct{}
+	// doneC is closed once Run returns.
+	doneC chan struct{}
+}
 
 // WithMemoryLimit sets the linux resource memory limit field.
 func WithMemoryLimit(limit int64) oci.SpecOpts {
@@ -43,14 +56,36 @@ func WithRootfsPropagation(rp string) oci.SpecOpts {
 	}
 }
 
-// Run implements the Runner interface.
-// nolint: gocyclo
-func (c *Containerd) Run(data *userdata.UserData, args runner.Args, setters ...runner.Option) error {
+// Run implements the Runner interface. It blocks for the lifetime of the
+// service.
+func (c *Containerd) Run(data *userdata.UserData, args *runner.Args, setters ...runner.Option) error {
+	c.mu.Lock()
+	stopC := make(chan struct{})
+	doneC := make(chan struct{})
+	c.stopC = stopC
+	c.doneC = doneC
+	c.mu.Unlock()
+
+	defer close(doneC)
+
+	// The waits before the task is run are cancelled once the service is
+	// stopped, so that Stop does not block on containerd or on a registry.
+
+	stopCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-stopC:
+			cancel()
+		case <-stopCtx.Done():
+		}
+	}()
+
 	// Wait for the containerd socket.
 
-	_, err := conditions.WaitForFileToExist(defaults.DefaultAddress)()
+	_, err := conditions.WaitForFileToExist(defaults.DefaultAddress).Wait(stopCtx)
 	if err != nil {
-		return err
+		return stopped(stopC, err)
 	}
 
 	// Create the default runner options.
@@ -59,6 +94,10 @@ func (c *Containerd) Run(data *userdata.UserData, args runner.Args, setters ...r
 	for _, setter := range setters {
 		setter(opts)
 	}
+	// The crash loop policy of the user data takes precedence.
+	for _, setter := range runner.CrashLoopOptions(data, args.ID) {
+		setter(opts)
+	}
 
 	// Create the containerd client.
 
@@ -70,9 +109,11 @@ func (c *Containerd) Run(data *userdata.UserData, args runner.Args, setters ...r
 	// nolint: errcheck
 	defer client.Close()
 
-	image, err := client.GetImage(ctx, opts.ContainerImage)
+	// Get the image, pulling it if it was not imported.
+
+	image, err := GetOrPullImage(namespaces.WithNamespace(stopCtx, opts.Namespace), client, data.Image(opts.ContainerImage), data.Registries())
 	if err != nil {
-		return err
+		return stopped(stopC, err)
 	}
 
 	// Create the container.
@@ -87,66 +128,136 @@ func (c *Containerd) Run(data *userdata.UserData, args runner.Args, setters ...r
 	if err != nil {
 		return fmt.Errorf("failed to create container %q: %v", args.ID, err)
 	}
+	defer container.Delete(ctx, containerd.WithSnapshotCleanup) // nolint: errcheck
+
+	// Run the task according to the restart policy.
+
+	return c.Supervise(args.ID, opts, stopC, func() error {
+		return c.runTask(ctx, container, args, opts, stopC)
+	})
+}
+
+// Stop implements the Runner interface.
+func (c *Containerd) Stop() error {
+	c.mu.Lock()
+	stopC, doneC := c.stopC, c.doneC
+	if stopC != nil {
+		select {
+		case <-stopC:
+		default:
+			close(stopC)
+		}
+	}
+	c.mu.Unlock()
+
+	if doneC != nil {
+		<-doneC
+	}
+
+	return nil
+}
 
-	// Create the task and start it.
+// stopped returns nil if stopC is closed, since the error is then caused by
+// the service being stopped, and err otherwise.
+func stopped(stopC <-chan struct{}, err error) error {
+	select {
+	case <-stopC:
+		return nil
+	default:
+		return err
+	}
+}
 
-	task, err := container.NewTask(ctx, cio.LogFile(logPath(args)))
+// runTask creates and starts a task for the container, and waits for it to
+// exit. An error is returned if the task could not be run, or if it exited
+// with a non-zero exit code. If stopC is closed while the task is running,
+// the task is sent SIGTERM, and then SIGKILL if it does not exit within the
+// graceful shutdown timeout.
+// nolint: gocyclo
+func (c *Containerd) runTask(ctx context.Context, container containerd.Container, args *runner.Args, opts *runner.Options, stopC <-chan struct{}) error {
+	// The output of the task is copied to the log, rather than written to it
+	// by the shim, so that the lines are timestamped as they are captured.
+	f, err := os.OpenFile(logPath(args), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open log: %q: %v", args.ID, err)
+	}
+	// nolint: errcheck
+	defer f.Close()
+	ts := filechunker.NewTimestamper(f)
+	task, err := container.NewTask(ctx, cio.NewCreator(cio.WithStreams(nil, ts.Writer(), ts.Writer())))
 	if err != nil {
 		return fmt.Errorf("failed to create task: %q: %v", args.ID, err)
 	}
+	defer task.Delete(ctx) // nolint: errcheck
+
+	// Wait must be called before Start to avoid missing the exit event.
+	statusC, err := task.Wait(ctx)
+	if err != nil {
+		return fmt.Errorf("failed waiting for task: %q: %v", args.ID, err)
+	}
 	if err := task.Start(ctx); err != nil {
 		return fmt.Errorf("failed to start task: %q: %v", args.ID, err)
 	}
 
-	// Wait for the task exit code.
-
-	if opts.Type == runner.Once {
-		defer container.Delete(ctx, containerd.WithSnapshotCleanup) // nolint: errcheck
-		defer task.Delete(ctx)                                      // nolint: errcheck
-		statusC, err := task.Wait(ctx)
-		if err != nil {
-			return fmt.Errorf("failed waiting for task: %q: %v", args.ID, err)
+	var status containerd.ExitStatus
+	select {
+	case status = <-statusC:
+	case <-stopC:
+		if err = task.Kill(ctx, unix.SIGTERM, containerd.WithKillAll); err != nil {
+			return fmt.Errorf("failed to send SIGTERM to task: %q: %v", args.ID, err)
 		}
-		status := <-statusC
-		code := status.ExitCode()
-		if code != 0 {
-			return fmt.Errorf("task %q failed: exit code %d", args.ID, code)
+		select {
+		case <-statusC:
+		case <-time.After(opts.GracefulShutdownTimeout):
+			log.Printf("task %q did not exit after %s, killing it", args.ID, opts.GracefulShutdownTimeout)
+			if err = task.Kill(ctx, unix.SIGKILL, containerd.WithKillAll); err != nil {
+				return fmt.Errorf("failed to send SIGKILL to task: %q: %v", args.ID, err)
+			}
+			<-statusC
 		}
+		return nil
+	}
+
+	code := status.ExitCode()
+	if code != 0 {
+		return fmt.Errorf("task %q failed: exit code %d", args.ID, code)
 	}
 
 	return nil
 }
 
-func newContainerOpts(image containerd.Image, args runner.Args, opts *runner.Options, specOpts []oci.SpecOpts) []containerd.NewContainerOpts {
+func newContainerOpts(image containerd.Image, args *runner.Args, opts *runner.Options, specOpts []oci.SpecOpts) []containerd.NewContainerOpts {
 	containerOpts := []containerd.NewContainerOpts{
 		containerd.WithImage(image),
 		containerd.WithNewSnapshot(args.ID, image),
 		containerd.WithNewSpec(specOpts...),
 	}
-	if opts.Type == runner.Forever {
-		containerOpts = append(containerOpts, restart.WithStatus(containerd.Running), restart.WithLogPath(logPath(args)))
-	}
 	containerOpts = append(containerOpts, opts.ContainerOpts...)
 
 	return containerOpts
 }
 
-func newOCISpecOpts(image oci.Image, args runner.Args, opts *runner.Options) []oci.SpecOpts {
+func newOCISpecOpts(image oci.Image, args *runner.Args, opts *runner.Options) []oci.SpecOpts {
 	specOpts := []oci.SpecOpts{
 		oci.WithImageConfig(image),
-		oci.WithProcessArgs(args.ProcessArgs...),
+	}
+	// Without process arguments, the entrypoint of the image is run.
+	if len(args.ProcessArgs) > 0 {
+		specOpts = append(specOpts, oci.WithProcessArgs(args.ProcessArgs...))
+	}
+	specOpts = append(specOpts,
 		oci.WithEnv(opts.Env),
 		oci.WithHostNamespace(specs.NetworkNamespace),
 		oci.WithHostNamespace(specs.PIDNamespace),
 		oci.WithHostHostsFile,
 		oci.WithHostResolvconf,
 		oci.WithPrivileged,
-	}
+	)
 	specOpts = append(specOpts, opts.OCISpecOpts...)
 
 	return specOpts
 }
 
-func logPath(args runner.Args) string {
+func logPath(args *runner.Args) string {
 	return "/var/log/" + args.ID + ".log"
 }
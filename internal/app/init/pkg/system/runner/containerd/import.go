@@ -24,7 +24,7 @@ type ImportRequest struct {
 
 // Import imports the images specified by the import requests.
 func Import(namespace string, reqs ...*ImportRequest) (err error) {
-	_, err = conditions.WaitForFileToExist(defaults.DefaultAddress)()
+	_, err = conditions.WaitForFileToExist(defaults.DefaultAddress).Wait(context.Background())
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,170 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package containerd
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/autonomy/talos/internal/pkg/userdata"
+	"github.com/containerd/containerd"
+	"github.com/containerd/containerd/errdefs"
+	"github.com/containerd/containerd/reference"
+	"github.com/containerd/containerd/remotes"
+	"github.com/containerd/containerd/remotes/docker"
+)
+
+// GetOrPullImage returns the image with the specified reference. If the image
+// does not exist in the namespace of the context, it is pulled from the
+// mirrors of its registry, and then from the registry itself. If the
+// reference contains a digest, the image must match it.
+func GetOrPullImage(ctx context.Context, client *containerd.Client, ref string, registries *userdata.Registries) (image containerd.Image, err error) {
+	image, err = client.GetImage(ctx, ref)
+	if err != nil {
+		if !errdefs.IsNotFound(err) {
+			return nil, err
+		}
+		if image, err = Pull(ctx, client, ref, registries); err != nil {
+			return nil, err
+		}
+	}
+
+	spec, err := reference.Parse(ref)
+	if err != nil {
+		// Images imported from tarballs are not required to have a valid
+		// reference.
+		return image, nil
+	}
+	if dgst := spec.Digest(); dgst != "" && image.Target().Digest != dgst {
+		return nil, fmt.Errorf("image %q has digest %s, expected %s", ref, image.Target().Digest, dgst)
+	}
+
+	return image, nil
+}
+
+// Pull pulls and unpacks the image with the specified reference. The mirrors
+// of the image's registry are tried in order before the registry itself.
+func Pull(ctx context.Context, client *containerd.Client, ref string, registries *userdata.Registries) (image containerd.Image, err error) {
+	spec, err := reference.Parse(ref)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse image reference %q: %v", ref, err)
+	}
+
+	for _, endpoint := range endpoints(spec.Hostname(), registries) {
+		var resolver remotes.Resolver
+		if resolver, err = newResolver(endpoint, registries); err != nil {
+			log.Printf("failed to configure registry endpoint %s: %v", endpoint, err)
+			continue
+		}
+
+		log.Printf("pulling %s from %s", ref, endpoint)
+		image, err = client.Pull(ctx, ref, containerd.WithResolver(resolver), containerd.WithPullUnpack)
+		if err == nil {
+			return image, nil
+		}
+		log.Printf("failed to pull %s from %s: %v", ref, endpoint, err)
+	}
+
+	return nil, fmt.Errorf("failed to pull %s: %v", ref, err)
+}
+
+// endpoints returns the URLs of the mirrors of the registry, followed by the
+// URL of the registry itself.
+func endpoints(host string, registries *userdata.Registries) []string {
+	endpoints := []string{}
+	if registries != nil {
+		if mirror, ok := registries.Mirrors[host]; ok {
+			endpoints = append(endpoints, mirror.Endpoints...)
+		}
+	}
+
+	if host == "docker.io" {
+		host = "registry-1.docker.io"
+	}
+
+	return append(endpoints, "https://"+host)
+}
+
+// newResolver returns a resolver that sends all requests to the endpoint,
+// using the credentials and the TLS settings configured for its host. Any
+// path in the endpoint is ignored.
+func newResolver(endpoint string, registries *userdata.Registries) (remotes.Resolver, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	var config *userdata.RegistryConfig
+	if registries != nil {
+		config = registries.Config[u.Host]
+	}
+
+	transport := &http.Transport{Proxy: http.ProxyFromEnvironment}
+	if config != nil && config.TLS != nil {
+		if transport.TLSClientConfig, err = tlsConfig(config.TLS); err != nil {
+			return nil, err
+		}
+	}
+	httpClient := &http.Client{Transport: transport}
+
+	return docker.NewResolver(docker.ResolverOptions{
+		Authorizer: docker.NewAuthorizer(httpClient, func(string) (string, string, error) {
+			if config == nil || config.Auth == nil {
+				return "", "", nil
+			}
+			return credentials(config.Auth)
+		}),
+		Host: func(string) (string, error) {
+			return u.Host, nil
+		},
+		PlainHTTP: u.Scheme == "http",
+		Client:    httpClient,
+	}), nil
+}
+
+func tlsConfig(t *userdata.RegistryTLS) (*tls.Config, error) {
+	config := &tls.Config{InsecureSkipVerify: t.InsecureSkipVerify} // nolint: gosec
+
+	if t.CA != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM([]byte(t.CA)) {
+			return nil, fmt.Errorf("failed to parse the registry CA")
+		}
+		config.RootCAs = pool
+	}
+
+	return config, nil
+}
+
+// credentials returns the username and the secret used by the authorizer. An
+// empty username means that the secret is an identity token.
+func credentials(auth *userdata.RegistryAuth) (string, string, error) {
+	switch {
+	case auth.IdentityToken != "":
+		return "", auth.IdentityToken, nil
+	case auth.Auth != "":
+		decoded, err := base64.StdEncoding.DecodeString(auth.Auth)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to decode registry auth: %v", err)
+		}
+		parts := strings.SplitN(string(decoded), ":", 2)
+		if len(parts) != 2 {
+			return "", "", fmt.Errorf("registry auth must be of the form username:password")
+		}
+		return parts[0], parts[1], nil
+	default:
+		return auth.Username, auth.Password, nil
+	}
+}
@@ -0,0 +1,54 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package containerd
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/autonomy/talos/internal/pkg/userdata"
+)
+
+// nolint: scopelint
+func Test_endpoints(t *testing.T) {
+	registries := &userdata.Registries{
+		Mirrors: map[string]*userdata.RegistryMirror{
+			"docker.io": {Endpoints: []string{"http://127.0.0.1:5000", "https://mirror.local"}},
+		},
+	}
+
+	type args struct {
+		host       string
+		registries *userdata.Registries
+	}
+	tests := []struct {
+		name string
+		args args
+		want []string
+	}{
+		{
+			name: "no registries",
+			args: args{host: "k8s.gcr.io"},
+			want: []string{"https://k8s.gcr.io"},
+		},
+		{
+			name: "no mirrors",
+			args: args{host: "k8s.gcr.io", registries: registries},
+			want: []string{"https://k8s.gcr.io"},
+		},
+		{
+			name: "mirrors",
+			args: args{host: "docker.io", registries: registries},
+			want: []string{"http://127.0.0.1:5000", "https://mirror.local", "https://registry-1.docker.io"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := endpoints(tt.args.host, tt.args.registries); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("endpoints() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package runner
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/autonomy/talos/internal/pkg/userdata"
+)
+
+// CrashLoopAction describes what is done about a crash looping process.
+type CrashLoopAction int
+
+const (
+	// CrashLoopBackoff keeps restarting the process at the maximum backoff.
+	CrashLoopBackoff CrashLoopAction = iota
+	// CrashLoopStop stops restarting the process.
+	CrashLoopStop
+	// CrashLoopReboot stops restarting the process, and asks for the node
+	// to be rebooted.
+	CrashLoopReboot
+)
+
+func (a CrashLoopAction) String() string {
+	switch a {
+	case CrashLoopBackoff:
+		return "backoff"
+	case CrashLoopStop:
+		return "stop"
+	case CrashLoopReboot:
+		return "reboot"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseCrashLoopAction returns the action with the specified name.
+func ParseCrashLoopAction(s string) (CrashLoopAction, error) {
+	for _, a := range []CrashLoopAction{CrashLoopBackoff, CrashLoopStop, CrashLoopReboot} {
+		if a.String() == s {
+			return a, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown crash loop action %q", s)
+}
+
+// CrashLoopError is returned by Supervise once a process is crash looping,
+// if the action is not CrashLoopBackoff.
+type CrashLoopError struct {
+	ID     string
+	Action CrashLoopAction
+	Exits  int
+	Window time.Duration
+	// Err is the error returned by the last run of the process.
+	Err error
+}
+
+func (e *CrashLoopError) Error() string {
+	msg := fmt.Sprintf("%q is crash looping: exited %d times within %s", e.ID, e.Exits, e.Window)
+	if e.Err != nil {
+		msg += fmt.Sprintf(": %v", e.Err)
+	}
+
+	return msg
+}
+
+// CrashLoopOptions returns the options that apply the crash loop policy of
+// the user data to the service with the specified ID. Invalid settings are
+// logged and ignored.
+func CrashLoopOptions(data *userdata.UserData, id string) (opts []Option) {
+	if data == nil || data.Services == nil || data.Services.Init == nil || data.Services.Init.CrashLoop == nil {
+		return nil
+	}
+
+	crashLoop := data.Services.Init.CrashLoop
+	policies := []*userdata.CrashLoopPolicy{&crashLoop.CrashLoopPolicy}
+	if policy, ok := crashLoop.Services[id]; ok && policy != nil {
+		policies = append(policies, policy)
+	}
+
+	for _, policy := range policies {
+		if policy.Threshold > 0 {
+			opts = append(opts, WithCrashLoopThreshold(policy.Threshold))
+		}
+		if policy.Window != "" {
+			window, err := time.ParseDuration(policy.Window)
+			if err != nil {
+				log.Printf("invalid crash loop window for %q: %v", id, err)
+			} else {
+				opts = append(opts, WithCrashLoopWindow(window))
+			}
+		}
+		if policy.Action != "" {
+			action, err := ParseCrashLoopAction(policy.Action)
+			if err != nil {
+				log.Printf("invalid crash loop action for %q: %v", id, err)
+			} else {
+				opts = append(opts, WithCrashLoopAction(action))
+			}
+		}
+	}
+
+	return opts
+}
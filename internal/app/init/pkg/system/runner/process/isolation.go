@@ -0,0 +1,181 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package process
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/autonomy/talos/internal/app/init/pkg/system/runner"
+	"github.com/containerd/cgroups"
+	specs "github.com/opencontainers/runtime-spec/specs-go"
+	"github.com/syndtr/gocapability/capability"
+	"golang.org/x/sys/unix"
+)
+
+// cgroupRoot is where the cgroup hierarchies are mounted by mount/cgroups.
+const cgroupRoot = "/sys/fs/cgroup"
+
+// cpuPeriod is the CFS period used to enforce CPU limits, in microseconds.
+const cpuPeriod = 100000
+
+// hierarchy returns the cgroup controllers that processes are placed into.
+func hierarchy() ([]cgroups.Subsystem, error) {
+	return []cgroups.Subsystem{
+		cgroups.NewCpu(cgroupRoot),
+		cgroups.NewFreezer(cgroupRoot),
+		cgroups.NewMemory(cgroupRoot),
+		cgroups.NewPids(cgroupRoot),
+	}, nil
+}
+
+// sysProcAttr returns the credentials and namespaces of the process.
+func sysProcAttr(opts *runner.Options) *syscall.SysProcAttr {
+	attr := &syscall.SysProcAttr{
+		Cloneflags: opts.Namespaces,
+	}
+
+	if opts.UID != 0 || opts.GID != 0 {
+		attr.Credential = &syscall.Credential{Uid: opts.UID, Gid: opts.GID}
+	}
+
+	return attr
+}
+
+// start starts the command with the isolation described by the options. The
+// command is started from a dedicated OS thread that first joins the cgroup
+// and drops the capabilities outside of the bounding set, so that the process
+// inherits both from the moment it is created. The thread is never unlocked,
+// which causes the Go runtime to destroy it once the command has started.
+// The cgroup of the process, if any, is returned so that it can be deleted
+// with deleteCgroup once the process has been waited on. If an error is
+// returned, the process is not running and its cgroup is deleted.
+func start(cmd *exec.Cmd, opts *runner.Options) (control cgroups.Cgroup, err error) {
+	var drop []capability.Cap
+	if opts.Capabilities != nil {
+		if drop, err = capabilitiesToDrop(opts.Capabilities); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.Cgroup != "" {
+		if control, err = cgroups.New(hierarchy, cgroups.StaticPath(opts.Cgroup), resources(opts)); err != nil {
+			return nil, fmt.Errorf("failed to create cgroup %q: %v", opts.Cgroup, err)
+		}
+	}
+
+	cmd.SysProcAttr = sysProcAttr(opts)
+
+	errC := make(chan error, 1)
+	go func() {
+		runtime.LockOSThread()
+
+		if control != nil {
+			if err := control.AddTask(cgroups.Process{Pid: unix.Gettid()}); err != nil {
+				errC <- fmt.Errorf("failed to join cgroup %q: %v", opts.Cgroup, err)
+				return
+			}
+		}
+
+		for _, c := range drop {
+			// Capabilities that are not supported by the kernel are
+			// rejected with EINVAL.
+			if err := unix.Prctl(unix.PR_CAPBSET_DROP, uintptr(c), 0, 0, 0); err != nil && err != unix.EINVAL {
+				errC <- fmt.Errorf("failed to drop capability %s: %v", c, err)
+				return
+			}
+		}
+
+		errC <- cmd.Start()
+	}()
+
+	if err = <-errC; err != nil {
+		deleteCgroup(control, opts.Cgroup)
+		return nil, err
+	}
+
+	if opts.OOMScoreAdj != 0 {
+		path := fmt.Sprintf("/proc/%d/oom_score_adj", cmd.Process.Pid)
+		if err = ioutil.WriteFile(path, []byte(strconv.Itoa(opts.OOMScoreAdj)), 0644); err != nil {
+			// nolint: errcheck
+			cmd.Process.Kill()
+			// nolint: errcheck
+			cmd.Wait()
+			deleteCgroup(control, opts.Cgroup)
+			return nil, fmt.Errorf("failed to set the OOM score adjustment: %v", err)
+		}
+	}
+
+	return control, nil
+}
+
+// deleteCgroup deletes the cgroup of a process once it has exited, so that a
+// restarting process does not leave a cgroup behind for each run. The cgroup
+// is left in place if it still has tasks, such as orphaned children of the
+// process.
+func deleteCgroup(control cgroups.Cgroup, path string) {
+	if control == nil {
+		return
+	}
+	if err := control.Delete(); err != nil {
+		log.Printf("failed to delete cgroup %q: %v", path, err)
+	}
+}
+
+func resources(opts *runner.Options) *specs.LinuxResources {
+	resources := &specs.LinuxResources{}
+
+	if opts.MemoryLimit > 0 {
+		limit := opts.MemoryLimit
+		resources.Memory = &specs.LinuxMemory{Limit: &limit}
+	}
+
+	if opts.CPULimit > 0 {
+		period := uint64(cpuPeriod)
+		quota := opts.CPULimit * cpuPeriod / 1000
+		resources.CPU = &specs.LinuxCPU{Period: &period, Quota: &quota}
+	}
+
+	return resources
+}
+
+// capabilitiesToDrop returns the capabilities that are not in the bounding
+// set.
+func capabilitiesToDrop(bounding []string) ([]capability.Cap, error) {
+	keep := map[capability.Cap]bool{}
+	for _, name := range bounding {
+		c, ok := parseCapability(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown capability %q", name)
+		}
+		keep[c] = true
+	}
+
+	drop := []capability.Cap{}
+	for _, c := range capability.List() {
+		if !keep[c] {
+			drop = append(drop, c)
+		}
+	}
+
+	return drop, nil
+}
+
+func parseCapability(name string) (capability.Cap, bool) {
+	name = strings.TrimPrefix(strings.ToUpper(name), "CAP_")
+	for _, c := range capability.List() {
+		if strings.ToUpper(c.String()) == name {
+			return c, true
+		}
+	}
+
+	return 0, false
+}
@@ -31,7 +31,9 @@ func New(name string) (*Log, error) {
 		return l, nil
 	}
 	logpath := FormatLogPath(name)
-	w, err := os.Create(logpath)
+	// The log is opened in append mode, so that writes continue at the end
+	// of the file after it has been truncated by log rotation.
+	w, err := os.OpenFile(logpath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY|os.O_APPEND, 0666)
 	if err != nil {
 		return nil, fmt.Errorf("create log file: %s", err.Error())
 	}
@@ -49,6 +51,16 @@ func New(name string) (*Log, error) {
 	return l, nil
 }
 
+// Exists reports whether a log has been registered for the service.
+func Exists(name string) bool {
+	mu.Lock()
+	defer mu.Unlock()
+
+	_, ok := instance[name]
+
+	return ok
+}
+
 // Write implements io.WriteCloser.
 func (l *Log) Write(p []byte) (n int, err error) {
 	return l.source.Write(p)
@@ -59,9 +71,16 @@ func (l *Log) Close() error {
 	return l.source.Close()
 }
 
-// Read implements chunker.Chunker.
+// Read implements chunker.Chunker. The log is read through its own file
+// descriptor, since the chunker seeks and closes its source.
 func (l *Log) Read(ctx context.Context) <-chan []byte {
-	c := filechunker.NewChunker(l.source)
+	r, err := os.Open(l.Path)
+	if err != nil {
+		ch := make(chan []byte)
+		close(ch)
+		return ch
+	}
+	c := filechunker.NewChunker(r, filechunker.Follow(true))
 	return c.Read(ctx)
 }
 
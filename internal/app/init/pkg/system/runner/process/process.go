@@ -10,16 +10,27 @@ import (
 	"log"
 	"os"
 	"os/exec"
+	"sync"
 	"time"
 
 	"github.com/autonomy/talos/internal/app/init/pkg/system/runner"
 	processlogger "github.com/autonomy/talos/internal/app/init/pkg/system/runner/process/log"
+	filechunker "github.com/autonomy/talos/internal/pkg/chunker/file"
 	"github.com/autonomy/talos/internal/pkg/constants"
 	"github.com/autonomy/talos/internal/pkg/userdata"
+	"golang.org/x/sys/unix"
 )
 
 // Process is a runner.Runner that runs a process on the host.
-type Process struct{}
+type Process struct {
+	runner.Restarter
+
+	mu sync.Mutex
+	// stopC is closed to stop the process.
+	stopC chan struct{}
+	// doneC is closed once Run returns.
+	doneC chan struct{}
+}
 
 // Run implements the Runner interface.
 func (p *Process) Run(data *userdata.UserData, args *runner.Args, setters ...runner.Option) error {
@@ -27,17 +38,41 @@ func (p *Process) Run(data *userdata.UserData, args *runner.Args, setters ...run
 	for _, setter := range setters {
 		setter(opts)
 	}
+	// The crash loop policy of the user data takes precedence.
+	for _, setter := range runner.CrashLoopOptions(data, args.ID) {
+		setter(opts)
+	}
 
-	switch opts.Type {
-	case runner.Forever:
-		if err := p.waitAndRestart(data, args, opts); err != nil {
-			return err
-		}
-	case runner.Once:
-		if err := p.waitForSuccess(data, args, opts); err != nil {
-			return err
+	p.mu.Lock()
+	stopC := make(chan struct{})
+	doneC := make(chan struct{})
+	p.stopC = stopC
+	p.doneC = doneC
+	p.mu.Unlock()
+
+	defer close(doneC)
+
+	return p.Supervise(args.ID, opts, stopC, func() error {
+		return p.run(data, args, opts, stopC)
+	})
+}
+
+// Stop implements the Runner interface.
+func (p *Process) Stop() error {
+	p.mu.Lock()
+	stopC, doneC := p.stopC, p.doneC
+	if stopC != nil {
+		select {
+		case <-stopC:
+		default:
+			close(stopC)
 		}
 	}
+	p.mu.Unlock()
+
+	if doneC != nil {
+		<-doneC
+	}
 
 	return nil
 }
@@ -55,60 +90,56 @@ func (p *Process) build(data *userdata.UserData, args *runner.Args, opts *runner
 		return
 	}
 
-	var writer io.Writer
+	// The lines are timestamped as they are captured, so that the log can be
+	// filtered by time.
+	ts := filechunker.NewTimestamper(w)
+	stdout, stderr := ts.Writer(), ts.Writer()
 	if data.Debug {
-		writer = io.MultiWriter(w, os.Stdout)
-	} else {
-		writer = w
+		stdout = io.MultiWriter(stdout, os.Stdout)
+		stderr = io.MultiWriter(stderr, os.Stdout)
 	}
-	cmd.Stdout = writer
-	cmd.Stderr = writer
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
 
 	return cmd, nil
 }
 
-func (p *Process) waitAndRestart(data *userdata.UserData, args *runner.Args, opts *runner.Options) (err error) {
+// run starts the process and waits for it to exit. If stopC is closed while
+// the process is running, the process is sent SIGTERM, and then SIGKILL if it
+// does not exit within the graceful shutdown timeout.
+func (p *Process) run(data *userdata.UserData, args *runner.Args, opts *runner.Options, stopC <-chan struct{}) (err error) {
 	cmd, err := p.build(data, args, opts)
 	if err != nil {
-		log.Printf("%v", err)
-		time.Sleep(5 * time.Second)
-		return p.waitAndRestart(data, args, opts)
+		return err
 	}
-	if err = cmd.Start(); err != nil {
-		log.Printf("%v", err)
-		time.Sleep(5 * time.Second)
-		return p.waitAndRestart(data, args, opts)
-	}
-	state, err := cmd.Process.Wait()
+	control, err := start(cmd, opts)
 	if err != nil {
-		log.Printf("%v", err)
-		time.Sleep(5 * time.Second)
-		return p.waitAndRestart(data, args, opts)
-	}
-	if state.Exited() {
-		time.Sleep(5 * time.Second)
-		return p.waitAndRestart(data, args, opts)
+		return err
 	}
+	defer deleteCgroup(control, opts.Cgroup)
 
-	return nil
-}
+	waitC := make(chan error, 1)
+	go func() {
+		waitC <- cmd.Wait()
+	}()
 
-func (p *Process) waitForSuccess(data *userdata.UserData, args *runner.Args, opts *runner.Options) (err error) {
-	cmd, err := p.build(data, args, opts)
-	if err != nil {
-		return
-	}
-	if err = cmd.Start(); err != nil {
-		return
+	select {
+	case err = <-waitC:
+		return err
+	case <-stopC:
 	}
-	state, err := cmd.Process.Wait()
-	if err != nil {
-		return
-	}
-	if !state.Success() {
-		time.Sleep(5 * time.Second)
-		return p.waitForSuccess(data, args, opts)
+
+	// nolint: errcheck
+	cmd.Process.Signal(unix.SIGTERM)
+
+	select {
+	case err = <-waitC:
+	case <-time.After(opts.GracefulShutdownTimeout):
+		log.Printf("process %q did not exit after %s, killing it", args.ID, opts.GracefulShutdownTimeout)
+		// nolint: errcheck
+		cmd.Process.Kill()
+		err = <-waitC
 	}
 
-	return nil
+	return err
 }
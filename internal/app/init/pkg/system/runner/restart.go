@@ -0,0 +1,146 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package runner
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// Restarter implements the restart policies, and is shared by the runners so
+// that they restart processes the same way.
+type Restarter struct {
+	mu           sync.Mutex
+	restarts     int
+	crashLooping bool
+}
+
+// RestartCount returns the number of times the process has been restarted.
+func (r *Restarter) RestartCount() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.restarts
+}
+
+// CrashLooping returns true if the process is crash looping.
+func (r *Restarter) CrashLooping() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.crashLooping
+}
+
+func (r *Restarter) setCrashLooping(crashLooping bool) {
+	r.mu.Lock()
+	r.crashLooping = crashLooping
+	r.mu.Unlock()
+}
+
+// Supervise invokes run, and invokes it again according to the restart
+// policy each time it returns. It returns once the policy does not allow
+// another restart, or once stopC is closed. The time between restarts grows
+// exponentially, and is reset once a run lasts for the reset window. Once the
+// process exits more often than the crash loop threshold within the crash
+// loop window, the crash loop action is taken.
+// nolint: gocyclo
+func (r *Restarter) Supervise(id string, opts *Options, stopC <-chan struct{}, run func() error) error {
+	backoff := opts.InitialBackoff
+	consecutive := 0
+	exits := []time.Time{}
+
+	r.setCrashLooping(false)
+
+	for {
+		started := time.Now()
+		err := run()
+
+		select {
+		case <-stopC:
+			return nil
+		default:
+		}
+
+		switch opts.Type {
+		case Never:
+			return err
+		case OnFailure:
+			if err == nil {
+				return nil
+			}
+		}
+
+		if err != nil {
+			log.Printf("%q exited: %v", id, err)
+		}
+
+		if time.Since(started) >= opts.ResetWindow {
+			backoff = opts.InitialBackoff
+			consecutive = 0
+			exits = exits[:0]
+			if r.CrashLooping() {
+				log.Printf("%q is no longer crash looping", id)
+				r.setCrashLooping(false)
+			}
+		}
+
+		exits = recentExits(append(exits, time.Now()), opts.CrashLoopWindow)
+		if opts.CrashLoopThreshold > 0 && len(exits) >= opts.CrashLoopThreshold {
+			crashLoopErr := &CrashLoopError{
+				ID:     id,
+				Action: opts.CrashLoopAction,
+				Exits:  len(exits),
+				Window: opts.CrashLoopWindow,
+				Err:    err,
+			}
+			if !r.CrashLooping() {
+				log.Print(crashLoopErr)
+				r.setCrashLooping(true)
+			}
+			if opts.CrashLoopAction != CrashLoopBackoff {
+				return crashLoopErr
+			}
+			backoff = opts.MaxBackoff
+		}
+
+		if opts.MaxRestarts > 0 && consecutive >= opts.MaxRestarts {
+			if err == nil {
+				return fmt.Errorf("%q exceeded the maximum of %d restarts", id, opts.MaxRestarts)
+			}
+			return fmt.Errorf("%q exceeded the maximum of %d restarts: %v", id, opts.MaxRestarts, err)
+		}
+
+		log.Printf("restarting %q in %s", id, backoff)
+		select {
+		case <-stopC:
+			return nil
+		case <-time.After(backoff):
+		}
+
+		consecutive++
+		r.mu.Lock()
+		r.restarts++
+		r.mu.Unlock()
+
+		backoff = time.Duration(float64(backoff) * opts.BackoffMultiplier)
+		if backoff > opts.MaxBackoff {
+			backoff = opts.MaxBackoff
+		}
+	}
+}
+
+// recentExits drops the exits that happened before the window.
+func recentExits(exits []time.Time, window time.Duration) []time.Time {
+	cutoff := time.Now().Add(-window)
+	for i, exit := range exits {
+		if exit.After(cutoff) {
+			return exits[i:]
+		}
+	}
+
+	return exits[:0]
+}
@@ -0,0 +1,123 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package runner
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// nolint: scopelint
+func TestRestarter_Supervise(t *testing.T) {
+	errFailed := errors.New("failed")
+
+	type args struct {
+		policy      Type
+		maxRestarts int
+		results     []error
+	}
+	tests := []struct {
+		name         string
+		args         args
+		wantRuns     int
+		wantRestarts int
+		wantErr      bool
+	}{
+		{
+			name:     "never",
+			args:     args{policy: Never, results: []error{errFailed}},
+			wantRuns: 1,
+			wantErr:  true,
+		},
+		{
+			name:         "on failure",
+			args:         args{policy: OnFailure, results: []error{errFailed, errFailed, nil}},
+			wantRuns:     3,
+			wantRestarts: 2,
+		},
+		{
+			name:         "always with max restarts",
+			args:         args{policy: Always, maxRestarts: 2, results: []error{nil, errFailed, nil}},
+			wantRuns:     3,
+			wantRestarts: 2,
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DefaultOptions()
+			opts.Type = tt.args.policy
+			opts.MaxRestarts = tt.args.maxRestarts
+			opts.InitialBackoff = time.Millisecond
+			opts.MaxBackoff = 2 * time.Millisecond
+
+			var r Restarter
+			runs := 0
+			err := r.Supervise("test", opts, make(chan struct{}), func() error {
+				err := tt.args.results[runs]
+				runs++
+				return err
+			})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Supervise() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if runs != tt.wantRuns {
+				t.Errorf("Supervise() runs = %d, want %d", runs, tt.wantRuns)
+			}
+			if r.RestartCount() != tt.wantRestarts {
+				t.Errorf("RestartCount() = %d, want %d", r.RestartCount(), tt.wantRestarts)
+			}
+		})
+	}
+}
+
+func TestRestarter_SuperviseCrashLoop(t *testing.T) {
+	errFailed := errors.New("failed")
+
+	opts := DefaultOptions()
+	opts.InitialBackoff = time.Millisecond
+	opts.MaxBackoff = 2 * time.Millisecond
+	opts.CrashLoopThreshold = 3
+	opts.CrashLoopAction = CrashLoopStop
+
+	var r Restarter
+	runs := 0
+	err := r.Supervise("test", opts, make(chan struct{}), func() error {
+		runs++
+		return errFailed
+	})
+	crashLoopErr, ok := err.(*CrashLoopError)
+	if !ok {
+		t.Fatalf("Supervise() error = %v, want a *CrashLoopError", err)
+	}
+	if crashLoopErr.Err != errFailed {
+		t.Errorf("CrashLoopError.Err = %v, want %v", crashLoopErr.Err, errFailed)
+	}
+	if runs != 3 {
+		t.Errorf("Supervise() runs = %d, want 3", runs)
+	}
+	if !r.CrashLooping() {
+		t.Error("CrashLooping() = false, want true")
+	}
+
+	// The backoff action keeps restarting the process.
+	opts.CrashLoopAction = CrashLoopBackoff
+	runs = 0
+	stopC := make(chan struct{})
+	err = r.Supervise("test", opts, stopC, func() error {
+		runs++
+		if runs == 5 {
+			if !r.CrashLooping() {
+				t.Error("CrashLooping() = false, want true")
+			}
+			close(stopC)
+		}
+		return errFailed
+	})
+	if err != nil {
+		t.Errorf("Supervise() error = %v, want nil", err)
+	}
+}
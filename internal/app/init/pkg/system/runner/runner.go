@@ -5,6 +5,10 @@
 package runner
 
 import (
+	"fmt"
+	"strings"
+	"time"
+
 	"github.com/autonomy/talos/internal/pkg/userdata"
 	"github.com/containerd/containerd"
 	"github.com/containerd/containerd/oci"
@@ -12,7 +16,16 @@ import (
 
 // Runner describes the requirements for running a process.
 type Runner interface {
-	Run(*userdata.UserData, *Args, ...Option)
+	// Run runs the process according to its restart policy. It blocks until
+	// the process exits for good, or until the runner is stopped.
+	Run(*userdata.UserData, *Args, ...Option) error
+	// Stop stops the process and cancels any pending restarts.
+	Stop() error
+	// RestartCount returns the number of times the process has been
+	// restarted.
+	RestartCount() int
+	// CrashLooping returns true if the process is crash looping.
+	CrashLooping() bool
 }
 
 // Args represents the required options for services.
@@ -36,6 +49,57 @@ type Options struct {
 	Namespace string
 	// Type describes the service's restart policy.
 	Type Type
+	// InitialBackoff is the time to wait before the first restart.
+	InitialBackoff time.Duration
+	// MaxBackoff is the upper bound of the time to wait between restarts.
+	MaxBackoff time.Duration
+	// BackoffMultiplier is the factor by which the time to wait grows after
+	// each restart.
+	BackoffMultiplier float64
+	// MaxRestarts is the number of consecutive restarts after which the
+	// runner gives up. Zero means that there is no limit.
+	MaxRestarts int
+	// Cgroup is the path of the cgroup that the process is placed into,
+	// relative to the root of each cgroup hierarchy. If empty, the process
+	// stays in the cgroup of init. Only used by the process runner.
+	Cgroup string
+	// MemoryLimit is the memory limit of the cgroup in bytes. Zero means
+	// that there is no limit.
+	MemoryLimit int64
+	// CPULimit is the CPU limit of the cgroup in millicores. Zero means that
+	// there is no limit.
+	CPULimit int64
+	// OOMScoreAdj is the OOM score adjustment of the process. Only used by
+	// the process runner.
+	OOMScoreAdj int
+	// UID is the user ID that the process runs as. Only used by the process
+	// runner.
+	UID uint32
+	// GID is the group ID that the process runs as. Only used by the process
+	// runner.
+	GID uint32
+	// Capabilities is the capability bounding set of the process, for example
+	// "CAP_SYS_ADMIN". If nil, the bounding set is inherited from init. Only
+	// used by the process runner.
+	Capabilities []string
+	// Namespaces is a combination of the CLONE_NEW* flags, describing the new
+	// namespaces that the process is run in. Only used by the process runner.
+	Namespaces uintptr
+	// ResetWindow is the time after which a running process is considered
+	// stable. If the process runs for at least this long, the backoff and the
+	// count of consecutive restarts are reset.
+	ResetWindow time.Duration
+	// GracefulShutdownTimeout is the time to wait for the process to exit
+	// after it has been sent SIGTERM, before it is sent SIGKILL.
+	GracefulShutdownTimeout time.Duration
+	// CrashLoopThreshold is the number of exits within the crash loop window
+	// after which the process is considered to be crash looping. Zero means
+	// that crash loops are not detected.
+	CrashLoopThreshold int
+	// CrashLoopWindow is the window in which exits are counted.
+	CrashLoopWindow time.Duration
+	// CrashLoopAction is what is done once the process is crash looping.
+	CrashLoopAction CrashLoopAction
 }
 
 // Option is the functional option func.
@@ -45,18 +109,54 @@ type Option func(*Options)
 type Type int
 
 const (
-	// Forever will always restart a process.
-	Forever Type = iota
-	// Once will restart the process only if it did not exit successfully.
-	Once
+	// Always will always restart a process.
+	Always Type = iota
+	// OnFailure will restart the process only if it did not exit
+	// successfully.
+	OnFailure
+	// Never will never restart a process.
+	Never
 )
 
+func (t Type) String() string {
+	switch t {
+	case Always:
+		return "Always"
+	case OnFailure:
+		return "OnFailure"
+	case Never:
+		return "Never"
+	default:
+		return "Unknown"
+	}
+}
+
+// ParseType returns the restart policy with the specified name. The name is
+// matched case insensitively.
+func ParseType(s string) (Type, error) {
+	for _, t := range []Type{Always, OnFailure, Never} {
+		if strings.EqualFold(t.String(), s) {
+			return t, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown restart policy %q", s)
+}
+
 // DefaultOptions describes the default options to a runner.
 func DefaultOptions() *Options {
 	return &Options{
-		Env:       []string{},
-		Type:      Forever,
-		Namespace: "system",
+		Env:                     []string{},
+		Type:                    Always,
+		Namespace:               "system",
+		GracefulShutdownTimeout: 10 * time.Second,
+		InitialBackoff:          5 * time.Second,
+		MaxBackoff:              5 * time.Minute,
+		BackoffMultiplier:       2,
+		ResetWindow:             10 * time.Minute,
+		CrashLoopThreshold:      5,
+		CrashLoopWindow:         5 * time.Minute,
+		CrashLoopAction:         CrashLoopBackoff,
 	}
 }
 
@@ -67,6 +167,105 @@ func WithType(o Type) Option {
 	}
 }
 
+// WithBackoff sets the time to wait before the first restart, the upper bound
+// of the time to wait, and the factor by which it grows after each restart.
+func WithBackoff(initial, max time.Duration, multiplier float64) Option {
+	return func(args *Options) {
+		args.InitialBackoff = initial
+		args.MaxBackoff = max
+		args.BackoffMultiplier = multiplier
+	}
+}
+
+// WithMaxRestarts sets the number of consecutive restarts after which the
+// runner gives up.
+func WithMaxRestarts(o int) Option {
+	return func(args *Options) {
+		args.MaxRestarts = o
+	}
+}
+
+// WithResetWindow sets the time after which a running process is considered
+// stable.
+func WithResetWindow(o time.Duration) Option {
+	return func(args *Options) {
+		args.ResetWindow = o
+	}
+}
+
+// WithCrashLoopThreshold sets the number of exits after which the process is
+// considered to be crash looping.
+func WithCrashLoopThreshold(o int) Option {
+	return func(args *Options) {
+		args.CrashLoopThreshold = o
+	}
+}
+
+// WithCrashLoopWindow sets the window in which exits are counted.
+func WithCrashLoopWindow(o time.Duration) Option {
+	return func(args *Options) {
+		args.CrashLoopWindow = o
+	}
+}
+
+// WithCrashLoopAction sets what is done once the process is crash looping.
+func WithCrashLoopAction(o CrashLoopAction) Option {
+	return func(args *Options) {
+		args.CrashLoopAction = o
+	}
+}
+
+// WithCgroup sets the cgroup that the process is placed into.
+func WithCgroup(o string) Option {
+	return func(args *Options) {
+		args.Cgroup = o
+	}
+}
+
+// WithMemoryLimit sets the memory limit of the process's cgroup in bytes.
+func WithMemoryLimit(o int64) Option {
+	return func(args *Options) {
+		args.MemoryLimit = o
+	}
+}
+
+// WithCPULimit sets the CPU limit of the process's cgroup in millicores.
+func WithCPULimit(o int64) Option {
+	return func(args *Options) {
+		args.CPULimit = o
+	}
+}
+
+// WithOOMScoreAdj sets the OOM score adjustment of the process.
+func WithOOMScoreAdj(o int) Option {
+	return func(args *Options) {
+		args.OOMScoreAdj = o
+	}
+}
+
+// WithCredentials sets the user and group IDs that the process runs as.
+func WithCredentials(uid, gid uint32) Option {
+	return func(args *Options) {
+		args.UID = uid
+		args.GID = gid
+	}
+}
+
+// WithCapabilities sets the capability bounding set of the process.
+func WithCapabilities(o ...string) Option {
+	return func(args *Options) {
+		args.Capabilities = o
+	}
+}
+
+// WithNamespaces sets the CLONE_NEW* flags of the new namespaces that the
+// process is run in.
+func WithNamespaces(o uintptr) Option {
+	return func(args *Options) {
+		args.Namespaces = o
+	}
+}
+
 // WithEnv sets the environment variables of a service.
 func WithEnv(o []string) Option {
 	return func(args *Options) {
@@ -74,6 +273,14 @@ func WithEnv(o []string) Option {
 	}
 }
 
+// WithGracefulShutdownTimeout sets the time to wait for the process to exit
+// when it is stopped.
+func WithGracefulShutdownTimeout(o time.Duration) Option {
+	return func(args *Options) {
+		args.GracefulShutdownTimeout = o
+	}
+}
+
 // WithNamespace sets the tar file to load.
 func WithNamespace(o string) Option {
 	return func(args *Options) {
@@ -0,0 +1,388 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package system
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/autonomy/talos/internal/app/init/pkg/system/conditions"
+	"github.com/autonomy/talos/internal/app/init/pkg/system/health"
+	"github.com/autonomy/talos/internal/app/init/pkg/system/runner"
+	"github.com/autonomy/talos/internal/pkg/constants"
+	"github.com/autonomy/talos/internal/pkg/userdata"
+	"github.com/pkg/errors"
+)
+
+// ServiceRunner wraps a Service and tracks the state of the service as it
+// moves through its lifecycle.
+type ServiceRunner struct {
+	mu sync.Mutex
+
+	data    *userdata.UserData
+	service Service
+	id      string
+
+	state       ServiceState
+	events      ServiceEvents
+	healthState health.State
+
+	// stopC is closed to stop the current run of the service.
+	stopC chan struct{}
+	// doneC is closed once the current run of the service returns. It is nil
+	// if the service has never been started.
+	doneC chan struct{}
+	// started is true once the current run has handed the service to its
+	// runner.
+	started bool
+	// reboot asks for the node to be rebooted.
+	reboot func()
+}
+
+// NewServiceRunner initializes and returns a ServiceRunner. The reboot func
+// is invoked if the service is crash looping, and its policy is to reboot the
+// node.
+func NewServiceRunner(service Service, data *userdata.UserData, reboot func()) *ServiceRunner {
+	return &ServiceRunner{
+		data:    data,
+		service: service,
+		id:      service.ID(data),
+		state:   StateWaiting,
+		reboot:  reboot,
+	}
+}
+
+// ID returns the service id.
+func (svcrunner *ServiceRunner) ID() string {
+	return svcrunner.id
+}
+
+// Namespace returns the containerd namespace of the service. Services that
+// do not implement NamespacedService run in the system namespace.
+func (svcrunner *ServiceRunner) Namespace() string {
+	if namespaced, ok := svcrunner.service.(NamespacedService); ok {
+		return namespaced.Namespace(svcrunner.data)
+	}
+
+	return constants.SystemContainerdNamespace
+}
+
+// State returns the current state of the service.
+func (svcrunner *ServiceRunner) State() ServiceState {
+	svcrunner.mu.Lock()
+	defer svcrunner.mu.Unlock()
+
+	return svcrunner.state
+}
+
+// Health returns the status of the service's health checks.
+func (svcrunner *ServiceRunner) Health() health.Status {
+	return svcrunner.healthState.Get()
+}
+
+// RestartCount returns the number of times the service has been restarted.
+func (svcrunner *ServiceRunner) RestartCount() int {
+	return svcrunner.service.RestartCount(svcrunner.data)
+}
+
+// Events returns the history of the service's state transitions, oldest
+// first.
+func (svcrunner *ServiceRunner) Events() []ServiceEvent {
+	svcrunner.mu.Lock()
+	defer svcrunner.mu.Unlock()
+
+	return svcrunner.events.Get()
+}
+
+// UpdateState records a state transition of the service. The message is
+// also written to the log.
+func (svcrunner *ServiceRunner) UpdateState(state ServiceState, msg string, args ...interface{}) {
+	svcrunner.mu.Lock()
+	defer svcrunner.mu.Unlock()
+
+	event := ServiceEvent{
+		Message:   fmt.Sprintf(msg, args...),
+		State:     state,
+		Timestamp: time.Now(),
+	}
+
+	svcrunner.state = state
+	svcrunner.events.Push(event)
+
+	log.Printf("service %q: %s: %s", svcrunner.id, state, event.Message)
+}
+
+// Start runs the service in a goroutine. It returns ErrServiceRunning if
+// the service has been started and has not yet returned.
+func (svcrunner *ServiceRunner) Start(dependencies []string, readyC func(string) <-chan struct{}, markReady func(string)) error {
+	svcrunner.mu.Lock()
+	defer svcrunner.mu.Unlock()
+
+	if svcrunner.doneC != nil {
+		select {
+		case <-svcrunner.doneC:
+		default:
+			return ErrServiceRunning
+		}
+	}
+
+	svcrunner.stopC = make(chan struct{})
+	svcrunner.doneC = make(chan struct{})
+	svcrunner.started = false
+
+	go svcrunner.run(dependencies, readyC, markReady, svcrunner.stopC, svcrunner.doneC)
+
+	return nil
+}
+
+// Stop stops the service and waits for it to return. It returns
+// ErrServiceNotRunning if the service is not running.
+func (svcrunner *ServiceRunner) Stop() error {
+	svcrunner.mu.Lock()
+	doneC := svcrunner.doneC
+	if doneC == nil {
+		svcrunner.mu.Unlock()
+		return ErrServiceNotRunning
+	}
+	select {
+	case <-doneC:
+		svcrunner.mu.Unlock()
+		return ErrServiceNotRunning
+	default:
+	}
+	close(svcrunner.stopC)
+	started := svcrunner.started
+	svcrunner.mu.Unlock()
+
+	if !started {
+		<-doneC
+		return nil
+	}
+
+	// The service may not have set up its runner by the time Stop is
+	// invoked, so keep stopping it until it returns. The runners block until
+	// they return, so the stops are invoked in the background, one at a
+	// time, so that a blocked stop does not prevent the next attempt from
+	// being made once it returns.
+	errC := make(chan error, 1)
+	stop := func() {
+		go func() {
+			errC <- svcrunner.service.Stop(svcrunner.data)
+		}()
+	}
+	stop()
+	pending := true
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-doneC:
+			return nil
+		case err := <-errC:
+			if err != nil {
+				return err
+			}
+			pending = false
+		case <-ticker.C:
+			if !pending {
+				stop()
+				pending = true
+			}
+		}
+	}
+}
+
+// run invokes the service's Pre, Condition, Start, and Post funcs, recording
+// the state of the service along the way. Before running any stage, the
+// service waits for the channel returned by readyC to be closed for each of
+// its dependencies. It calls markReady once it has been started, or once it
+// is healthy if it is health checked.
+// nolint: gocyclo
+func (svcrunner *ServiceRunner) run(dependencies []string, readyC func(string) <-chan struct{}, markReady func(string), stopC, doneC chan struct{}) {
+	defer close(doneC)
+
+	for _, id := range dependencies {
+		svcrunner.UpdateState(StateWaiting, "Waiting for service %q", id)
+		select {
+		case <-readyC(id):
+		case <-stopC:
+			svcrunner.UpdateState(StateFinished, "Service stopped")
+			return
+		}
+	}
+
+	svcrunner.UpdateState(StatePreparing, "Running pre stage")
+	if err := svcrunner.service.PreFunc(svcrunner.data); err != nil {
+		svcrunner.UpdateState(StateFailed, "Failed to run pre stage: %v", err)
+		return
+	}
+
+	condition := svcrunner.service.Condition(svcrunner.data)
+	svcrunner.UpdateState(StateWaiting, "Waiting for %s", condition)
+	ok, err := waitForCondition(condition, stopC)
+	if err != nil {
+		select {
+		case <-stopC:
+			svcrunner.UpdateState(StateFinished, "Service stopped")
+		default:
+			svcrunner.UpdateState(StateFailed, "Condition failed: %v", err)
+		}
+		return
+	}
+	if !ok {
+		svcrunner.UpdateState(StateSkipped, "Condition not met")
+		return
+	}
+
+	svcrunner.mu.Lock()
+	select {
+	case <-stopC:
+		svcrunner.mu.Unlock()
+		svcrunner.UpdateState(StateFinished, "Service stopped")
+		return
+	default:
+		svcrunner.started = true
+	}
+	svcrunner.mu.Unlock()
+
+	// The runners may block for the lifetime of the service, so the service
+	// is considered running once it has been handed to its runner.
+	svcrunner.UpdateState(StateRunning, "Starting service")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		svcrunner.watchCrashLoop(ctx)
+	}()
+
+	if healthchecked, ok := svcrunner.service.(HealthcheckedService); ok {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			svcrunner.runHealthChecks(ctx, healthchecked, markReady)
+		}()
+	} else {
+		markReady(svcrunner.id)
+	}
+
+	err = svcrunner.service.Start(svcrunner.data)
+
+	// Stop the health checks before recording the outcome, so that a late
+	// result does not overwrite it.
+	cancel()
+	wg.Wait()
+
+	if crashLoopErr, ok := errors.Cause(err).(*runner.CrashLoopError); ok {
+		svcrunner.UpdateState(StateCrashLooping, "Stopped restarting service: %v", crashLoopErr)
+		if crashLoopErr.Action == runner.CrashLoopReboot && svcrunner.reboot != nil {
+			svcrunner.UpdateState(StateCrashLooping, "Rebooting the node")
+			svcrunner.reboot()
+		}
+		return
+	}
+	if err != nil {
+		svcrunner.UpdateState(StateFailed, "Failed to start service: %v", err)
+		return
+	}
+
+	select {
+	case <-stopC:
+		svcrunner.UpdateState(StateFinished, "Service stopped")
+		return
+	default:
+	}
+
+	if err := svcrunner.service.PostFunc(svcrunner.data); err != nil {
+		svcrunner.UpdateState(StateFailed, "Failed to run post stage: %v", err)
+		return
+	}
+
+	svcrunner.UpdateState(StateFinished, "Service finished successfully")
+}
+
+// runHealthChecks runs the health checks of the service until the context is
+// canceled. Changes in health are recorded as events, and the service is
+// marked as ready the first time it is healthy.
+func (svcrunner *ServiceRunner) runHealthChecks(ctx context.Context, service HealthcheckedService, markReady func(string)) {
+	settings := service.HealthSettings(svcrunner.data)
+	if settings == nil {
+		settings = &health.DefaultSettings
+	}
+
+	changes := make(chan health.StateChange, 1)
+	svcrunner.healthState.Subscribe(changes)
+	defer svcrunner.healthState.Unsubscribe(changes)
+
+	go health.Run(ctx, settings, &svcrunner.healthState, service.HealthFunc(svcrunner.data))
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-changes:
+			// The notifications are dropped while one is pending, so the
+			// current status is read rather than the one of the change.
+			status := svcrunner.healthState.Get()
+			if status.Healthy == nil {
+				continue
+			}
+			if *status.Healthy {
+				svcrunner.UpdateState(StateRunning, "Health check successful")
+				markReady(svcrunner.id)
+			} else {
+				svcrunner.UpdateState(StateRunning, "Health check failed: %s", status.LastMessage)
+			}
+		}
+	}
+}
+
+// watchCrashLoop records the changes in whether the service is crash
+// looping until the context is canceled.
+func (svcrunner *ServiceRunner) watchCrashLoop(ctx context.Context) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	crashLooping := false
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		current := svcrunner.service.CrashLooping(svcrunner.data)
+		switch {
+		case current && !crashLooping:
+			svcrunner.UpdateState(StateCrashLooping, "Service is crash looping")
+		case !current && crashLooping:
+			svcrunner.UpdateState(StateRunning, "Service is no longer crash looping")
+		}
+		crashLooping = current
+	}
+}
+
+// waitForCondition waits for the condition, and cancels the wait once stopC
+// is closed.
+func waitForCondition(condition conditions.Condition, stopC <-chan struct{}) (bool, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		select {
+		case <-stopC:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	return condition.Wait(ctx)
+}
@@ -20,7 +20,9 @@ import (
 
 // Blockd implements the Service interface. It serves as the concrete type with
 // the required methods.
-type Blockd struct{}
+type Blockd struct {
+	runner containerd.Containerd
+}
 
 // ID implements the Service interface.
 func (t *Blockd) ID(data *userdata.UserData) string {
@@ -37,16 +39,36 @@ func (t *Blockd) PostFunc(data *userdata.UserData) (err error) {
 	return nil
 }
 
-// ConditionFunc implements the Service interface.
-func (t *Blockd) ConditionFunc(data *userdata.UserData) conditions.ConditionFunc {
+// Condition implements the Service interface.
+func (t *Blockd) Condition(data *userdata.UserData) conditions.Condition {
 	return conditions.None()
 }
 
+// DependsOn implements the Service interface.
+func (t *Blockd) DependsOn(data *userdata.UserData) []string {
+	return []string{"containerd"}
+}
+
+// Stop implements the Service interface.
+func (t *Blockd) Stop(data *userdata.UserData) error {
+	return t.runner.Stop()
+}
+
+// RestartCount implements the Service interface.
+func (t *Blockd) RestartCount(data *userdata.UserData) int {
+	return t.runner.RestartCount()
+}
+
+// CrashLooping implements the Service interface.
+func (t *Blockd) CrashLooping(data *userdata.UserData) bool {
+	return t.runner.CrashLooping()
+}
+
 func (t *Blockd) Start(data *userdata.UserData) error {
 	image := "talos/blockd"
 
 	// Set the process arguments.
-	args := runner.Args{
+	args := &runner.Args{
 		ID:          t.ID(data),
 		ProcessArgs: []string{"/blockd", "--userdata=" + constants.UserDataPath},
 	}
@@ -64,9 +86,7 @@ func (t *Blockd) Start(data *userdata.UserData) error {
 		env = append(env, fmt.Sprintf("%s=%s", key, val))
 	}
 
-	r := containerd.Containerd{}
-
-	return r.Run(
+	return t.runner.Run(
 		data,
 		args,
 		runner.WithContainerImage(image),
@@ -5,19 +5,25 @@
 package services
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/autonomy/talos/internal/app/init/pkg/system/conditions"
+	"github.com/autonomy/talos/internal/app/init/pkg/system/health"
 	"github.com/autonomy/talos/internal/app/init/pkg/system/runner"
 	"github.com/autonomy/talos/internal/app/init/pkg/system/runner/process"
 	"github.com/autonomy/talos/internal/pkg/userdata"
+	"github.com/containerd/containerd"
 	"github.com/containerd/containerd/defaults"
 )
 
 // Containerd implements the Service interface. It serves as the concrete type with
 // the required methods.
-type Containerd struct{}
+type Containerd struct {
+	runner process.Process
+}
 
 // ID implements the Service interface.
 func (c *Containerd) ID(data *userdata.UserData) string {
@@ -34,11 +40,59 @@ func (c *Containerd) PostFunc(data *userdata.UserData) (err error) {
 	return nil
 }
 
-// ConditionFunc implements the Service interface.
-func (c *Containerd) ConditionFunc(data *userdata.UserData) conditions.ConditionFunc {
+// Condition implements the Service interface.
+func (c *Containerd) Condition(data *userdata.UserData) conditions.Condition {
 	return conditions.None()
 }
 
+// DependsOn implements the Service interface.
+func (c *Containerd) DependsOn(data *userdata.UserData) []string {
+	return nil
+}
+
+// Stop implements the Service interface.
+func (c *Containerd) Stop(data *userdata.UserData) error {
+	return c.runner.Stop()
+}
+
+// RestartCount implements the Service interface.
+func (c *Containerd) RestartCount(data *userdata.UserData) int {
+	return c.runner.RestartCount()
+}
+
+// CrashLooping implements the Service interface.
+func (c *Containerd) CrashLooping(data *userdata.UserData) bool {
+	return c.runner.CrashLooping()
+}
+
+// HealthFunc implements the HealthcheckedService interface. Containerd is
+// healthy once it answers version requests on its socket, so that the
+// services that depend on it do not race its startup.
+func (c *Containerd) HealthFunc(data *userdata.UserData) health.Check {
+	return func(ctx context.Context) error {
+		client, err := containerd.New(defaults.DefaultAddress, containerd.WithTimeout(time.Second))
+		if err != nil {
+			return err
+		}
+		// nolint: errcheck
+		defer client.Close()
+
+		_, err = client.Version(ctx)
+
+		return err
+	}
+}
+
+// HealthSettings implements the HealthcheckedService interface. The checks
+// start early and run often, since most services wait for containerd.
+func (c *Containerd) HealthSettings(data *userdata.UserData) *health.Settings {
+	return &health.Settings{
+		InitialDelay: 100 * time.Millisecond,
+		Period:       time.Second,
+		Timeout:      2 * time.Second,
+	}
+}
+
 // Start implements the Service interface.
 func (c *Containerd) Start(data *userdata.UserData) error {
 	// Set the process arguments.
@@ -52,11 +106,11 @@ func (c *Containerd) Start(data *userdata.UserData) error {
 		env = append(env, fmt.Sprintf("%s=%s", key, val))
 	}
 
-	r := process.Process{}
-
-	return r.Run(
+	return c.runner.Run(
 		data,
 		args,
 		runner.WithEnv(env),
+		runner.WithCgroup("/system/containerd"),
+		runner.WithOOMScoreAdj(-999),
 	)
 }
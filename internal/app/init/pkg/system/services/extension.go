@@ -0,0 +1,186 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+// nolint: golint
+package services
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/autonomy/talos/internal/app/init/pkg/system/conditions"
+	"github.com/autonomy/talos/internal/app/init/pkg/system/runner"
+	"github.com/autonomy/talos/internal/app/init/pkg/system/runner/containerd"
+	"github.com/autonomy/talos/internal/pkg/userdata"
+	"github.com/containerd/containerd/identifiers"
+	"github.com/containerd/containerd/oci"
+	specs "github.com/opencontainers/runtime-spec/specs-go"
+)
+
+// Extension implements the Service interface for a system container declared
+// in the user data.
+type Extension struct {
+	Spec *userdata.Extension
+
+	runner containerd.Containerd
+}
+
+// builtins are the IDs of the built-in system services, which extensions may
+// not take.
+var builtins = map[string]struct{}{
+	"blockd":     {},
+	"containerd": {},
+	"kubeadm":    {},
+	"kubelet":    {},
+	"osd":        {},
+	"proxyd":     {},
+	"trustd":     {},
+	"udevd":      {},
+}
+
+// Validate checks the name of the extension. The name is used as the service
+// ID, the container ID, and the name of the log file, so it must be a valid
+// containerd identifier, and must not be the ID of a built-in service. An
+// extension that fails validation must not be registered.
+func (e *Extension) Validate() error {
+	if e.Spec.Name == "" {
+		return fmt.Errorf("extension with image %q: a name is required", e.Spec.Image)
+	}
+	if err := identifiers.Validate(e.Spec.Name); err != nil {
+		return fmt.Errorf("extension %q: invalid name: %v", e.Spec.Name, err)
+	}
+	if _, ok := builtins[e.Spec.Name]; ok {
+		return fmt.Errorf("extension %q: the name of a built-in service cannot be used", e.Spec.Name)
+	}
+
+	return nil
+}
+
+// ID implements the Service interface.
+func (e *Extension) ID(data *userdata.UserData) string {
+	return e.Spec.Name
+}
+
+// PreFunc implements the Service interface. The rest of the spec is validated
+// here, so that an invalid extension is reported as failed.
+func (e *Extension) PreFunc(data *userdata.UserData) error {
+	if e.Spec.Image == "" {
+		return fmt.Errorf("extension %q: an image is required", e.Spec.Name)
+	}
+	if e.Spec.Restart != "" {
+		if _, err := runner.ParseType(e.Spec.Restart); err != nil {
+			return fmt.Errorf("extension %q: %v", e.Spec.Name, err)
+		}
+	}
+	for _, mount := range e.Spec.Mounts {
+		if mount.Source == "" || mount.Destination == "" {
+			return fmt.Errorf("extension %q: mounts require a source and a destination", e.Spec.Name)
+		}
+	}
+
+	return nil
+}
+
+// PostFunc implements the Service interface.
+func (e *Extension) PostFunc(data *userdata.UserData) (err error) {
+	return nil
+}
+
+// Condition implements the Service interface.
+func (e *Extension) Condition(data *userdata.UserData) conditions.Condition {
+	return conditions.None()
+}
+
+// DependsOn implements the Service interface.
+func (e *Extension) DependsOn(data *userdata.UserData) []string {
+	deps := []string{"containerd"}
+	for _, dep := range e.Spec.DependsOn {
+		if dep != "containerd" {
+			deps = append(deps, dep)
+		}
+	}
+
+	return deps
+}
+
+// Stop implements the Service interface.
+func (e *Extension) Stop(data *userdata.UserData) error {
+	return e.runner.Stop()
+}
+
+// RestartCount implements the Service interface.
+func (e *Extension) RestartCount(data *userdata.UserData) int {
+	return e.runner.RestartCount()
+}
+
+// CrashLooping implements the Service interface.
+func (e *Extension) CrashLooping(data *userdata.UserData) bool {
+	return e.runner.CrashLooping()
+}
+
+// Start implements the Service interface.
+func (e *Extension) Start(data *userdata.UserData) error {
+	restart := runner.Always
+	if e.Spec.Restart != "" {
+		var err error
+		if restart, err = runner.ParseType(e.Spec.Restart); err != nil {
+			return err
+		}
+	}
+
+	// Set the process arguments.
+	args := &runner.Args{
+		ID:          e.ID(data),
+		ProcessArgs: e.Spec.Args,
+	}
+
+	// Set the mounts.
+	mounts := []specs.Mount{}
+	for _, mount := range e.Spec.Mounts {
+		m := specs.Mount{
+			Type:        mount.Type,
+			Source:      mount.Source,
+			Destination: mount.Destination,
+			Options:     mount.Options,
+		}
+		if m.Type == "" {
+			m.Type = "bind"
+		}
+		if len(m.Options) == 0 {
+			m.Options = []string{"rbind", "ro"}
+		}
+		mounts = append(mounts, m)
+	}
+
+	// The environment of the extension takes precedence over the
+	// environment of the node.
+	vars := map[string]string{}
+	for key, val := range data.Env {
+		vars[key] = val
+	}
+	for key, val := range e.Spec.Env {
+		vars[key] = val
+	}
+	env := []string{}
+	for key, val := range vars {
+		env = append(env, fmt.Sprintf("%s=%s", key, val))
+	}
+	sort.Strings(env)
+
+	specOpts := []oci.SpecOpts{
+		oci.WithMounts(mounts),
+	}
+	if e.Spec.MemoryLimit > 0 {
+		specOpts = append(specOpts, containerd.WithMemoryLimit(e.Spec.MemoryLimit))
+	}
+
+	return e.runner.Run(
+		data,
+		args,
+		runner.WithType(restart),
+		runner.WithContainerImage(e.Spec.Image),
+		runner.WithEnv(env),
+		runner.WithOCISpecOpts(specOpts...),
+	)
+}
@@ -34,7 +34,9 @@ import (
 
 // Kubeadm implements the Service interface. It serves as the concrete type with
 // the required methods.
-type Kubeadm struct{}
+type Kubeadm struct {
+	runner containerd.Containerd
+}
 
 // ID implements the Service interface.
 func (k *Kubeadm) ID(data *userdata.UserData) string {
@@ -100,15 +102,38 @@ func (k *Kubeadm) PostFunc(data *userdata.UserData) error {
 	return nil
 }
 
-// ConditionFunc implements the Service interface.
-func (k *Kubeadm) ConditionFunc(data *userdata.UserData) conditions.ConditionFunc {
-	files := []string{defaults.DefaultAddress}
-
+// Condition implements the Service interface.
+func (k *Kubeadm) Condition(data *userdata.UserData) conditions.Condition {
 	if data.IsControlPlane() {
-		files = append(files, "/etc/kubernetes/admin.conf")
+		return conditions.WaitForFileToExist("/etc/kubernetes/admin.conf")
 	}
 
-	return conditions.WaitForFilesToExist(files...)
+	return conditions.None()
+}
+
+// DependsOn implements the Service interface.
+func (k *Kubeadm) DependsOn(data *userdata.UserData) []string {
+	return []string{"containerd"}
+}
+
+// Stop implements the Service interface.
+func (k *Kubeadm) Stop(data *userdata.UserData) error {
+	return k.runner.Stop()
+}
+
+// RestartCount implements the Service interface.
+func (k *Kubeadm) RestartCount(data *userdata.UserData) int {
+	return k.runner.RestartCount()
+}
+
+// Namespace implements the NamespacedService interface.
+func (k *Kubeadm) Namespace(data *userdata.UserData) string {
+	return criconstants.K8sContainerdNamespace
+}
+
+// CrashLooping implements the Service interface.
+func (k *Kubeadm) CrashLooping(data *userdata.UserData) bool {
+	return k.runner.CrashLooping()
 }
 
 // Start implements the Service interface.
@@ -122,7 +147,7 @@ func (k *Kubeadm) Start(data *userdata.UserData) error {
 	}
 
 	// Set the process arguments.
-	args := runner.Args{
+	args := &runner.Args{
 		ID: k.ID(data),
 	}
 	ignore := "--ignore-preflight-errors=cri,kubeletversion,numcpu,requiredipvskernelmodulesavailable"
@@ -149,12 +174,10 @@ func (k *Kubeadm) Start(data *userdata.UserData) error {
 		env = append(env, fmt.Sprintf("%s=%s", key, val))
 	}
 
-	r := containerd.Containerd{}
-
-	return r.Run(
+	return k.runner.Run(
 		data,
 		args,
-		runner.WithNamespace(criconstants.K8sContainerdNamespace),
+		runner.WithNamespace(k.Namespace(data)),
 		runner.WithContainerImage(image),
 		runner.WithEnv(env),
 		runner.WithOCISpecOpts(
@@ -165,7 +188,7 @@ func (k *Kubeadm) Start(data *userdata.UserData) error {
 			oci.WithParentCgroupDevices,
 			oci.WithPrivileged,
 		),
-		runner.WithType(runner.Once),
+		runner.WithType(runner.OnFailure),
 	)
 }
 
@@ -24,7 +24,9 @@ import (
 
 // Kubelet implements the Service interface. It serves as the concrete type with
 // the required methods.
-type Kubelet struct{}
+type Kubelet struct {
+	runner containerd.Containerd
+}
 
 // ID implements the Service interface.
 func (k *Kubelet) ID(data *userdata.UserData) string {
@@ -50,9 +52,34 @@ func (k *Kubelet) PostFunc(data *userdata.UserData) (err error) {
 	return nil
 }
 
-// ConditionFunc implements the Service interface.
-func (k *Kubelet) ConditionFunc(data *userdata.UserData) conditions.ConditionFunc {
-	return conditions.WaitForFilesToExist("/var/lib/kubelet/kubeadm-flags.env", defaults.DefaultAddress)
+// Condition implements the Service interface.
+func (k *Kubelet) Condition(data *userdata.UserData) conditions.Condition {
+	return conditions.WaitForFileToExist("/var/lib/kubelet/kubeadm-flags.env")
+}
+
+// DependsOn implements the Service interface.
+func (k *Kubelet) DependsOn(data *userdata.UserData) []string {
+	return []string{"containerd"}
+}
+
+// Stop implements the Service interface.
+func (k *Kubelet) Stop(data *userdata.UserData) error {
+	return k.runner.Stop()
+}
+
+// RestartCount implements the Service interface.
+func (k *Kubelet) RestartCount(data *userdata.UserData) int {
+	return k.runner.RestartCount()
+}
+
+// Namespace implements the NamespacedService interface.
+func (k *Kubelet) Namespace(data *userdata.UserData) string {
+	return criconstants.K8sContainerdNamespace
+}
+
+// CrashLooping implements the Service interface.
+func (k *Kubelet) CrashLooping(data *userdata.UserData) bool {
+	return k.runner.CrashLooping()
 }
 
 // Start implements the Service interface.
@@ -60,7 +87,7 @@ func (k *Kubelet) Start(data *userdata.UserData) error {
 	image := constants.KubernetesImage
 
 	// Set the process arguments.
-	args := runner.Args{
+	args := &runner.Args{
 		ID: k.ID(data),
 		ProcessArgs: []string{
 			"/hyperkube",
@@ -105,12 +132,10 @@ func (k *Kubelet) Start(data *userdata.UserData) error {
 		env = append(env, fmt.Sprintf("%s=%s", key, val))
 	}
 
-	r := containerd.Containerd{}
-
-	return r.Run(
+	return k.runner.Run(
 		data,
 		args,
-		runner.WithNamespace(criconstants.K8sContainerdNamespace),
+		runner.WithNamespace(k.Namespace(data)),
 		runner.WithContainerImage(image),
 		runner.WithEnv(env),
 		runner.WithOCISpecOpts(
@@ -121,6 +146,6 @@ func (k *Kubelet) Start(data *userdata.UserData) error {
 			oci.WithParentCgroupDevices,
 			oci.WithPrivileged,
 		),
-		runner.WithType(runner.Forever),
+		runner.WithType(runner.Always),
 	)
 }
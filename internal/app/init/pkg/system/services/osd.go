@@ -21,7 +21,9 @@ import (
 
 // OSD implements the Service interface. It serves as the concrete type with
 // the required methods.
-type OSD struct{}
+type OSD struct {
+	runner containerd.Containerd
+}
 
 // ID implements the Service interface.
 func (o *OSD) ID(data *userdata.UserData) string {
@@ -30,7 +32,13 @@ func (o *OSD) ID(data *userdata.UserData) string {
 
 // PreFunc implements the Service interface.
 func (o *OSD) PreFunc(data *userdata.UserData) error {
-	return os.MkdirAll("/etc/kubernetes", os.ModeDir)
+	for _, dir := range []string{"/etc/kubernetes", "/var/lib/kubelet"} {
+		if err := os.MkdirAll(dir, os.ModeDir); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
 // PostFunc implements the Service interface.
@@ -38,16 +46,36 @@ func (o *OSD) PostFunc(data *userdata.UserData) (err error) {
 	return nil
 }
 
-// ConditionFunc implements the Service interface.
-func (o *OSD) ConditionFunc(data *userdata.UserData) conditions.ConditionFunc {
+// Condition implements the Service interface.
+func (o *OSD) Condition(data *userdata.UserData) conditions.Condition {
 	return conditions.None()
 }
 
+// DependsOn implements the Service interface.
+func (o *OSD) DependsOn(data *userdata.UserData) []string {
+	return []string{"containerd"}
+}
+
+// Stop implements the Service interface.
+func (o *OSD) Stop(data *userdata.UserData) error {
+	return o.runner.Stop()
+}
+
+// RestartCount implements the Service interface.
+func (o *OSD) RestartCount(data *userdata.UserData) int {
+	return o.runner.RestartCount()
+}
+
+// CrashLooping implements the Service interface.
+func (o *OSD) CrashLooping(data *userdata.UserData) bool {
+	return o.runner.CrashLooping()
+}
+
 func (o *OSD) Start(data *userdata.UserData) error {
 	image := "talos/osd"
 
 	// Set the process arguments.
-	args := runner.Args{
+	args := &runner.Args{
 		ID:          o.ID(data),
 		ProcessArgs: []string{"/osd", "--userdata=" + constants.UserDataPath},
 	}
@@ -61,6 +89,7 @@ func (o *OSD) Start(data *userdata.UserData) error {
 		{Type: "bind", Destination: "/etc/kubernetes", Source: "/etc/kubernetes", Options: []string{"bind", "rw"}},
 		{Type: "bind", Destination: "/etc/ssl", Source: "/etc/ssl", Options: []string{"bind", "ro"}},
 		{Type: "bind", Destination: "/var/log", Source: "/var/log", Options: []string{"rbind", "rw"}},
+		{Type: "bind", Destination: "/var/lib/kubelet", Source: "/var/lib/kubelet", Options: []string{"rbind", "ro"}},
 	}
 
 	env := []string{}
@@ -68,9 +97,7 @@ func (o *OSD) Start(data *userdata.UserData) error {
 		env = append(env, fmt.Sprintf("%s=%s", key, val))
 	}
 
-	r := containerd.Containerd{}
-
-	return r.Run(
+	return o.runner.Run(
 		data,
 		args,
 		runner.WithContainerImage(image),
@@ -6,10 +6,15 @@
 package services
 
 import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
+	"io/ioutil"
 	"os"
 
 	"github.com/autonomy/talos/internal/app/init/pkg/system/conditions"
+	"github.com/autonomy/talos/internal/app/init/pkg/system/health"
 	"github.com/autonomy/talos/internal/app/init/pkg/system/runner"
 	"github.com/autonomy/talos/internal/app/init/pkg/system/runner/containerd"
 	"github.com/autonomy/talos/internal/pkg/userdata"
@@ -19,7 +24,9 @@ import (
 
 // Proxyd implements the Service interface. It serves as the concrete type with
 // the required methods.
-type Proxyd struct{}
+type Proxyd struct {
+	runner containerd.Containerd
+}
 
 // ID implements the Service interface.
 func (p *Proxyd) ID(data *userdata.UserData) string {
@@ -36,16 +43,60 @@ func (p *Proxyd) PostFunc(data *userdata.UserData) (err error) {
 	return nil
 }
 
-// ConditionFunc implements the Service interface.
-func (p *Proxyd) ConditionFunc(data *userdata.UserData) conditions.ConditionFunc {
+// Condition implements the Service interface.
+func (p *Proxyd) Condition(data *userdata.UserData) conditions.Condition {
 	return conditions.WaitForFilesToExist("/etc/kubernetes/pki/ca.crt", "/etc/kubernetes/admin.conf")
 }
 
+// DependsOn implements the Service interface.
+func (p *Proxyd) DependsOn(data *userdata.UserData) []string {
+	return []string{"containerd"}
+}
+
+// Stop implements the Service interface.
+func (p *Proxyd) Stop(data *userdata.UserData) error {
+	return p.runner.Stop()
+}
+
+// RestartCount implements the Service interface.
+func (p *Proxyd) RestartCount(data *userdata.UserData) int {
+	return p.runner.RestartCount()
+}
+
+// CrashLooping implements the Service interface.
+func (p *Proxyd) CrashLooping(data *userdata.UserData) bool {
+	return p.runner.CrashLooping()
+}
+
+// HealthFunc implements the HealthcheckedService interface. Requests to the
+// proxy are forwarded to an API server, so proxyd is healthy only once it can
+// reach an API server backend.
+func (p *Proxyd) HealthFunc(data *userdata.UserData) health.Check {
+	return func(ctx context.Context) error {
+		caCert, err := ioutil.ReadFile("/etc/kubernetes/pki/ca.crt")
+		if err != nil {
+			return err
+		}
+		certPool := x509.NewCertPool()
+		certPool.AppendCertsFromPEM(caCert)
+
+		// The API server certificate is always valid for "kubernetes".
+		config := &tls.Config{RootCAs: certPool, ServerName: "kubernetes"}
+
+		return health.HTTP("https://127.0.0.1:443/healthz", config)(ctx)
+	}
+}
+
+// HealthSettings implements the HealthcheckedService interface.
+func (p *Proxyd) HealthSettings(data *userdata.UserData) *health.Settings {
+	return &health.DefaultSettings
+}
+
 func (p *Proxyd) Start(data *userdata.UserData) error {
 	image := "talos/proxyd"
 
 	// Set the process arguments.
-	args := runner.Args{
+	args := &runner.Args{
 		ID:          p.ID(data),
 		ProcessArgs: []string{"/proxyd"},
 	}
@@ -61,9 +112,7 @@ func (p *Proxyd) Start(data *userdata.UserData) error {
 		env = append(env, fmt.Sprintf("%s=%s", key, val))
 	}
 
-	r := containerd.Containerd{}
-
-	return r.Run(
+	return p.runner.Run(
 		data,
 		args,
 		runner.WithContainerImage(image),
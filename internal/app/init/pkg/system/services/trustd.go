@@ -19,7 +19,9 @@ import (
 
 // Trustd implements the Service interface. It serves as the concrete type with
 // the required methods.
-type Trustd struct{}
+type Trustd struct {
+	runner containerd.Containerd
+}
 
 // ID implements the Service interface.
 func (t *Trustd) ID(data *userdata.UserData) string {
@@ -36,16 +38,36 @@ func (t *Trustd) PostFunc(data *userdata.UserData) (err error) {
 	return nil
 }
 
-// ConditionFunc implements the Service interface.
-func (t *Trustd) ConditionFunc(data *userdata.UserData) conditions.ConditionFunc {
+// Condition implements the Service interface.
+func (t *Trustd) Condition(data *userdata.UserData) conditions.Condition {
 	return conditions.None()
 }
 
+// DependsOn implements the Service interface.
+func (t *Trustd) DependsOn(data *userdata.UserData) []string {
+	return []string{"containerd"}
+}
+
+// Stop implements the Service interface.
+func (t *Trustd) Stop(data *userdata.UserData) error {
+	return t.runner.Stop()
+}
+
+// RestartCount implements the Service interface.
+func (t *Trustd) RestartCount(data *userdata.UserData) int {
+	return t.runner.RestartCount()
+}
+
+// CrashLooping implements the Service interface.
+func (t *Trustd) CrashLooping(data *userdata.UserData) bool {
+	return t.runner.CrashLooping()
+}
+
 func (t *Trustd) Start(data *userdata.UserData) error {
 	image := "talos/trustd"
 
 	// Set the process arguments.
-	args := runner.Args{
+	args := &runner.Args{
 		ID:          t.ID(data),
 		ProcessArgs: []string{"/trustd", "--userdata=" + constants.UserDataPath},
 	}
@@ -61,9 +83,7 @@ func (t *Trustd) Start(data *userdata.UserData) error {
 		env = append(env, fmt.Sprintf("%s=%s", key, val))
 	}
 
-	r := containerd.Containerd{}
-
-	return r.Run(
+	return t.runner.Run(
 		data,
 		args,
 		runner.WithContainerImage(image),
@@ -15,7 +15,9 @@ import (
 
 // Udevd implements the Service interface. It serves as the concrete type with
 // the required methods.
-type Udevd struct{}
+type Udevd struct {
+	runner process.Process
+}
 
 // ID implements the Service interface.
 func (c *Udevd) ID(data *userdata.UserData) string {
@@ -32,11 +34,31 @@ func (c *Udevd) PostFunc(data *userdata.UserData) (err error) {
 	return nil
 }
 
-// ConditionFunc implements the Service interface.
-func (c *Udevd) ConditionFunc(data *userdata.UserData) conditions.ConditionFunc {
+// Condition implements the Service interface.
+func (c *Udevd) Condition(data *userdata.UserData) conditions.Condition {
 	return conditions.None()
 }
 
+// DependsOn implements the Service interface.
+func (c *Udevd) DependsOn(data *userdata.UserData) []string {
+	return nil
+}
+
+// Stop implements the Service interface.
+func (c *Udevd) Stop(data *userdata.UserData) error {
+	return c.runner.Stop()
+}
+
+// RestartCount implements the Service interface.
+func (c *Udevd) RestartCount(data *userdata.UserData) int {
+	return c.runner.RestartCount()
+}
+
+// CrashLooping implements the Service interface.
+func (c *Udevd) CrashLooping(data *userdata.UserData) bool {
+	return c.runner.CrashLooping()
+}
+
 // Start implements the Service interface.
 func (c *Udevd) Start(data *userdata.UserData) error {
 	// Set the process arguments.
@@ -50,11 +72,12 @@ func (c *Udevd) Start(data *userdata.UserData) error {
 		env = append(env, fmt.Sprintf("%s=%s", key, val))
 	}
 
-	r := process.Process{}
-
-	return r.Run(
+	return c.runner.Run(
 		data,
 		args,
 		runner.WithEnv(env),
+		runner.WithCgroup("/system/udevd"),
+		runner.WithMemoryLimit(int64(1000000*256)),
+		runner.WithOOMScoreAdj(-500),
 	)
 }
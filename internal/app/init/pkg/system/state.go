@@ -0,0 +1,88 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package system
+
+import (
+	"time"
+)
+
+// ServiceState represents the state of a service.
+type ServiceState int
+
+const (
+	// StateWaiting indicates that the service is waiting for its dependencies
+	// or its condition.
+	StateWaiting ServiceState = iota
+	// StatePreparing indicates that the service's pre stage is running.
+	StatePreparing
+	// StateRunning indicates that the service has been started.
+	StateRunning
+	// StateFinished indicates that the service exited successfully.
+	StateFinished
+	// StateFailed indicates that a stage of the service failed.
+	StateFailed
+	// StateSkipped indicates that the service's condition was not met.
+	StateSkipped
+	// StateCrashLooping indicates that the service exits repeatedly.
+	StateCrashLooping
+)
+
+func (s ServiceState) String() string {
+	switch s {
+	case StateWaiting:
+		return "Waiting"
+	case StatePreparing:
+		return "Preparing"
+	case StateRunning:
+		return "Running"
+	case StateFinished:
+		return "Finished"
+	case StateFailed:
+		return "Failed"
+	case StateSkipped:
+		return "Skipped"
+	case StateCrashLooping:
+		return "CrashLooping"
+	default:
+		return "Unknown"
+	}
+}
+
+// MaxServiceEvents is the number of events kept in the history of a service.
+const MaxServiceEvents = 64
+
+// ServiceEvent describes a state transition of a service.
+type ServiceEvent struct {
+	Message   string
+	State     ServiceState
+	Timestamp time.Time
+}
+
+// ServiceEvents is a bounded history of service events. Once the history is
+// full, the oldest event is discarded for every new event.
+type ServiceEvents struct {
+	events []ServiceEvent
+	next   int
+}
+
+// Push appends an event to the history.
+func (e *ServiceEvents) Push(event ServiceEvent) {
+	if len(e.events) < MaxServiceEvents {
+		e.events = append(e.events, event)
+		return
+	}
+
+	e.events[e.next] = event
+	e.next = (e.next + 1) % MaxServiceEvents
+}
+
+// Get returns the events in the history, oldest first.
+func (e *ServiceEvents) Get() []ServiceEvent {
+	events := make([]ServiceEvent, 0, len(e.events))
+	events = append(events, e.events[e.next:]...)
+	events = append(events, e.events[:e.next]...)
+
+	return events
+}
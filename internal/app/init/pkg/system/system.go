@@ -5,20 +5,47 @@
 package system
 
 import (
+	"errors"
 	"log"
+	"sort"
 	"sync"
 
 	"github.com/autonomy/talos/internal/app/init/pkg/system/conditions"
+	"github.com/autonomy/talos/internal/app/init/pkg/system/health"
 	"github.com/autonomy/talos/internal/pkg/userdata"
 )
 
 type singleton struct {
 	UserData *userdata.UserData
+
+	mu sync.Mutex
+	// runners maps the ID of every registered service to its runner.
+	runners map[string]*ServiceRunner
+	// dependencies maps the ID of every registered service to the IDs of the
+	// services it depends on.
+	dependencies map[string][]string
+	// ready maps a service ID to a channel that is closed once the service
+	// is ready.
+	ready map[string]chan struct{}
+	// reboot is invoked when a crash looping service asks for the node to be
+	// rebooted.
+	reboot func()
 }
 
 var instance *singleton
 var once sync.Once
 
+var (
+	// ErrServiceNotFound is returned when a service is not registered.
+	ErrServiceNotFound = errors.New("service not found")
+	// ErrServiceRunning is returned when starting a service that is already
+	// running.
+	ErrServiceRunning = errors.New("service is already running")
+	// ErrServiceNotRunning is returned when stopping a service that is not
+	// running.
+	ErrServiceNotRunning = errors.New("service is not running")
+)
+
 // Service is an interface describing a system service.
 type Service interface {
 	// ID is the service id.
@@ -27,52 +54,228 @@ type Service interface {
 	PreFunc(*userdata.UserData) error
 	// Start
 	Start(*userdata.UserData) error
+	// Stop stops the service, and cancels any pending restarts. It must
+	// cause Start to return.
+	Stop(*userdata.UserData) error
+	// RestartCount returns the number of times the service has been
+	// restarted by its runner.
+	RestartCount(*userdata.UserData) int
+	// CrashLooping returns true if the service's runner considers the
+	// service to be crash looping.
+	CrashLooping(*userdata.UserData) bool
 	// PostFunc is invoked after a command is executed.
 	PostFunc(*userdata.UserData) error
-	// ConditionFunc describes the conditions under which a service should
+	// Condition describes the conditions under which a service should
 	// start.
-	ConditionFunc(*userdata.UserData) conditions.ConditionFunc
+	Condition(*userdata.UserData) conditions.Condition
+	// DependsOn returns the IDs of the services that must be ready before the
+	// service is started.
+	DependsOn(*userdata.UserData) []string
+}
+
+// HealthcheckedService is a service that reports its health. A service that
+// implements it is considered ready once its first health check succeeds,
+// rather than once it has been started.
+type HealthcheckedService interface {
+	Service
+	// HealthFunc returns the health check of the service.
+	HealthFunc(*userdata.UserData) health.Check
+	// HealthSettings returns the settings of the health check. If nil,
+	// health.DefaultSettings are used.
+	HealthSettings(*userdata.UserData) *health.Settings
+}
+
+// NamespacedService is a service that runs in a containerd namespace other
+// than the system namespace.
+type NamespacedService interface {
+	Service
+	// Namespace returns the containerd namespace of the service.
+	Namespace(*userdata.UserData) string
 }
 
-// Services returns the instance of the system services API.
-// TODO(andrewrynhard): This should be a gRPC based API availale on a local
-// unix socket.
+// Services returns the instance of the system services API. The API is
+// served to other processes over a local unix socket by the init API.
 // nolint: golint
 func Services(data *userdata.UserData) *singleton {
 	once.Do(func() {
-		instance = &singleton{UserData: data}
+		instance = &singleton{
+			UserData:     data,
+			runners:      map[string]*ServiceRunner{},
+			dependencies: map[string][]string{},
+			ready:        map[string]chan struct{}{},
+		}
 	})
 	return instance
 }
 
 // Start will invoke the service's Pre, Condition, and Type funcs. If the any
 // error occurs in the Pre or Condition invocations, it is up to the caller to
-// to restart the service.
+// to restart the service. Services are started in dependency order, and each
+// service waits for the services it depends on to be ready. A dependency
+// may be satisfied by a service passed to a later invocation of Start.
 func (s *singleton) Start(services ...Service) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ids := []string{}
 	for _, service := range services {
-		go func(service Service) {
-			id := service.ID(s.UserData)
-			if err := service.PreFunc(s.UserData); err != nil {
-				log.Printf("failed to run pre stage of service %q: %v", id, err)
-				return
-			}
-
-			_, err := service.ConditionFunc(s.UserData)()
-			if err != nil {
-				log.Printf("service %q condition failed: %v", id, err)
-				return
-			}
-
-			log.Printf("starting service %q", id)
-			if err := service.Start(s.UserData); err != nil {
-				log.Printf("failed to start service %q: %v", id, err)
-				return
-			}
-
-			if err := service.PostFunc(s.UserData); err != nil {
-				log.Printf("failed to run post stage of service %q: %v", id, err)
-				return
-			}
-		}(service)
+		id := service.ID(s.UserData)
+		if _, ok := s.runners[id]; ok {
+			log.Printf("service %q is already registered", id)
+			continue
+		}
+		s.runners[id] = NewServiceRunner(service, s.UserData, s.requestReboot)
+		s.dependencies[id] = service.DependsOn(s.UserData)
+		ids = append(ids, id)
+	}
+
+	order, err := dependencyOrder(s.dependencies, ids)
+	if err != nil {
+		for _, id := range ids {
+			s.runners[id].UpdateState(StateFailed, "Failed to resolve dependencies: %v", err)
+			delete(s.dependencies, id)
+		}
+		return
+	}
+
+	for _, id := range order {
+		s.runners[id].Start(s.dependencies[id], s.readyC, s.markReady)
+	}
+}
+
+// SetRebootFunc sets the func that is invoked when a crash looping service
+// asks for the node to be rebooted.
+func (s *singleton) SetRebootFunc(f func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.reboot = f
+}
+
+// StartService starts a registered service that is not running.
+func (s *singleton) StartService(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	svcrunner, ok := s.runners[id]
+	if !ok {
+		return ErrServiceNotFound
+	}
+
+	return svcrunner.Start(s.dependencies[id], s.readyC, s.markReady)
+}
+
+// StopService stops a running service, and waits for it to exit.
+func (s *singleton) StopService(id string) error {
+	svcrunner, ok := s.Get(id)
+	if !ok {
+		return ErrServiceNotFound
+	}
+
+	return svcrunner.Stop()
+}
+
+// RestartService stops a service if it is running, and starts it again.
+func (s *singleton) RestartService(id string) error {
+	if err := s.StopService(id); err != nil && err != ErrServiceNotRunning {
+		return err
+	}
+
+	return s.StartService(id)
+}
+
+// ShutdownOrder returns the IDs of the registered services in reverse
+// dependency order, so that every service comes before the services it
+// depends on.
+func (s *singleton) ShutdownOrder() ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ids := make([]string, 0, len(s.runners))
+	for id := range s.runners {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	order, err := dependencyOrder(s.dependencies, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, j := 0, len(order)-1; i < j; i, j = i+1, j-1 {
+		order[i], order[j] = order[j], order[i]
+	}
+
+	return order, nil
+}
+
+// List returns the runners of all registered services, sorted by ID.
+func (s *singleton) List() (runners []*ServiceRunner) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	runners = make([]*ServiceRunner, 0, len(s.runners))
+	for _, runner := range s.runners {
+		runners = append(runners, runner)
 	}
+	sort.Slice(runners, func(i, j int) bool { return runners[i].ID() < runners[j].ID() })
+
+	return runners
+}
+
+// Get returns the runner of the service with the specified ID.
+func (s *singleton) Get(id string) (runner *ServiceRunner, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	runner, ok = s.runners[id]
+
+	return runner, ok
+}
+
+// requestReboot invokes the reboot func, if set. The func is invoked in the
+// background, since the reboot stops the service asking for it.
+func (s *singleton) requestReboot() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.reboot == nil {
+		log.Printf("no reboot func set, ignoring reboot request")
+		return
+	}
+
+	go s.reboot()
+}
+
+// readyC returns the channel that is closed once the service with the
+// specified ID is ready.
+func (s *singleton) readyC(id string) <-chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.readyCLocked(id)
+}
+
+// markReady closes the channel returned by readyC for the service with the
+// specified ID. A service that is restarted remains marked as ready.
+func (s *singleton) markReady(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := s.readyCLocked(id)
+	select {
+	case <-c:
+	default:
+		close(c)
+	}
+}
+
+func (s *singleton) readyCLocked(id string) chan struct{} {
+	c, ok := s.ready[id]
+	if !ok {
+		c = make(chan struct{})
+		s.ready[id] = c
+	}
+
+	return c
 }
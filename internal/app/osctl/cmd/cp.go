@@ -0,0 +1,51 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+// nolint: dupl,golint
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/autonomy/talos/internal/app/osctl/internal/client"
+	"github.com/autonomy/talos/internal/app/osd/proto"
+	"github.com/autonomy/talos/internal/pkg/constants"
+	"github.com/spf13/cobra"
+)
+
+// cpCmd represents the cp command
+var cpCmd = &cobra.Command{
+	Use:   "cp <path> <local-dir|->",
+	Short: "Copy a file or a directory from a node",
+	Long:  `Copies a file or a directory in one of the roots that files can be read from into a local directory, or writes it to stdout as a tar archive if the destination is "-". Private keys are redacted unless --no-redact is set.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 2 {
+			if err := cmd.Usage(); err != nil {
+				os.Exit(1)
+			}
+			os.Exit(1)
+		}
+		creds, err := client.NewDefaultClientCredentials(talosconfig)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		creds.Nodes = nodes
+		c, err := client.NewClient(constants.OsdPort, creds)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		if err := c.CopyOut(&proto.CopyOutRequest{Path: args[0], NoRedact: noRedact}, args[1]); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	cpCmd.Flags().BoolVar(&noRedact, "no-redact", false, "do not redact private keys")
+	rootCmd.AddCommand(cpCmd)
+}
@@ -11,9 +11,12 @@ import (
 	"github.com/autonomy/talos/internal/pkg/constants"
 
 	"github.com/autonomy/talos/internal/app/osctl/internal/client"
+	"github.com/autonomy/talos/internal/app/osd/proto"
 	"github.com/spf13/cobra"
 )
 
+var level string
+
 // dmesgCmd represents the dmesg command
 var dmesgCmd = &cobra.Command{
 	Use:   "dmesg",
@@ -32,12 +35,17 @@ var dmesgCmd = &cobra.Command{
 			fmt.Println(err)
 			os.Exit(1)
 		}
+		creds.Nodes = nodes
 		c, err := client.NewClient(constants.OsdPort, creds)
 		if err != nil {
 			fmt.Println(err)
 			os.Exit(1)
 		}
-		if err := c.Dmesg(); err != nil {
+		r := &proto.DmesgRequest{
+			Follow: follow,
+			Level:  level,
+		}
+		if err := c.Dmesg(r); err != nil {
 			fmt.Println(err)
 			os.Exit(1)
 		}
@@ -45,5 +53,7 @@ var dmesgCmd = &cobra.Command{
 }
 
 func init() {
+	dmesgCmd.Flags().BoolVarP(&follow, "follow", "f", false, "stream new records")
+	dmesgCmd.Flags().StringVar(&level, "level", "", "the least severe priority to show, for example warn")
 	rootCmd.AddCommand(dmesgCmd)
 }
@@ -15,6 +15,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/autonomy/talos/internal/pkg/constants"
 	"github.com/autonomy/talos/internal/pkg/crypto/x509"
 	"github.com/spf13/cobra"
 )
@@ -115,6 +116,10 @@ var crtCmd = &cobra.Command{
 	Short: "Generates an X.509 ECDSA certificate",
 	Long:  ``,
 	Run: func(cmd *cobra.Command, args []string) {
+		if !isRoleOrganization(organization) {
+			fmt.Printf("invalid organization %q: must be one of %s, %s or %s\n", organization, constants.ReadOnlyOrganization, constants.OperatorOrganization, constants.AdminOrganization)
+			os.Exit(1)
+		}
 		caBytes, err := ioutil.ReadFile(ca + ".crt")
 		if err != nil {
 			fmt.Println(err)
@@ -158,7 +163,11 @@ var crtCmd = &cobra.Command{
 			fmt.Println(err)
 			os.Exit(1)
 		}
-		signedCrt, err := x509.NewCertificateFromCSR(caCrt, caKey, ccsr, x509.NotAfter(time.Now().Add(time.Duration(hours)*time.Hour)))
+		opts := []x509.Option{
+			x509.NotAfter(time.Now().Add(time.Duration(hours) * time.Hour)),
+			x509.Organization(organization),
+		}
+		signedCrt, err := x509.NewCertificateFromCSR(caCrt, caKey, ccsr, opts...)
 		if err != nil {
 			fmt.Println(err)
 			os.Exit(1)
@@ -201,6 +210,17 @@ var keypairCmd = &cobra.Command{
 	},
 }
 
+// isRoleOrganization reports whether the organization sets the role of an
+// osd client. The node organization is reserved for the node identities.
+func isRoleOrganization(o string) bool {
+	switch o {
+	case constants.ReadOnlyOrganization, constants.OperatorOrganization, constants.AdminOrganization:
+		return true
+	default:
+		return false
+	}
+}
+
 func init() {
 	// Certificate Authorities
 	caCmd.Flags().StringVar(&organization, "organization", "", "X.509 distinguished name for the Organization")
@@ -228,6 +248,10 @@ func init() {
 		os.Exit(1)
 	}
 	crtCmd.Flags().IntVar(&hours, "hours", 24, "the hours from now on which the certificate validity period ends")
+	crtCmd.Flags().StringVar(&organization, "organization", "", "X.509 distinguished name for the Organization, which sets the role of an osd client: os:readonly, os:operator or os:admin")
+	if err := cobra.MarkFlagRequired(crtCmd.Flags(), "organization"); err != nil {
+		os.Exit(1)
+	}
 	// Keypairs
 	keypairCmd.Flags().StringVar(&ip, "ip", "", "generate the certificate for this IP address")
 	keypairCmd.Flags().StringVar(&ca, "ca", "", "path to the PEM encoded CERTIFICATE")
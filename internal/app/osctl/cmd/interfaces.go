@@ -0,0 +1,44 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+// nolint: dupl,golint
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/autonomy/talos/internal/app/osctl/internal/client"
+	"github.com/autonomy/talos/internal/pkg/constants"
+	"github.com/spf13/cobra"
+)
+
+// interfacesCmd represents the net interfaces command
+var interfacesCmd = &cobra.Command{
+	Use:   "interfaces",
+	Short: "List network interfaces",
+	Long:  `Lists the network interfaces with their addresses, state and counters.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		creds, err := client.NewDefaultClientCredentials(talosconfig)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		creds.Nodes = nodes
+		c, err := client.NewClient(constants.OsdPort, creds)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		if err := c.Interfaces(); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(interfacesCmd)
+}
@@ -26,6 +26,7 @@ var kubeconfigCmd = &cobra.Command{
 			fmt.Println(err)
 			os.Exit(1)
 		}
+		creds.Nodes = nodes
 		c, err := client.NewClient(constants.OsdPort, creds)
 		if err != nil {
 			log.Fatal(err)
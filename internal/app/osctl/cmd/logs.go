@@ -7,14 +7,22 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/autonomy/talos/internal/app/osctl/internal/client"
 	"github.com/autonomy/talos/internal/app/osd/proto"
 	"github.com/autonomy/talos/internal/pkg/constants"
 	criconstants "github.com/containerd/cri/pkg/constants"
+	"github.com/golang/protobuf/ptypes"
 	"github.com/spf13/cobra"
 )
 
+var (
+	follow    bool
+	tailLines int32
+	since     time.Duration
+)
+
 // logsCmd represents the logs command
 var logsCmd = &cobra.Command{
 	Use:   "logs <id>",
@@ -32,6 +40,7 @@ var logsCmd = &cobra.Command{
 			fmt.Println(err)
 			os.Exit(1)
 		}
+		creds.Nodes = nodes
 		c, err := client.NewClient(constants.OsdPort, creds)
 		if err != nil {
 			fmt.Print(err)
@@ -46,6 +55,14 @@ var logsCmd = &cobra.Command{
 		r := &proto.LogsRequest{
 			Id:        args[0],
 			Namespace: namespace,
+			Follow:    follow,
+			TailLines: tailLines,
+		}
+		if since > 0 {
+			if r.Since, err = ptypes.TimestampProto(time.Now().Add(-since)); err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
 		}
 		if err := c.Logs(r); err != nil {
 			fmt.Print(err)
@@ -55,6 +72,9 @@ var logsCmd = &cobra.Command{
 }
 
 func init() {
+	logsCmd.Flags().BoolVarP(&follow, "follow", "f", false, "stream new output until the container exits")
+	logsCmd.Flags().Int32Var(&tailLines, "tail", 0, "the number of lines to show from the end of the log (0 for all)")
+	logsCmd.Flags().DurationVar(&since, "since", 0, "show only lines logged within the duration, for example 5m (only for logs with timestamps)")
 	logsCmd.Flags().BoolVarP(&kubernetes, "kubernetes", "k", false, "use the k8s.io containerd namespace")
 	rootCmd.AddCommand(logsCmd)
 }
@@ -0,0 +1,49 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+// nolint: dupl,golint
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/autonomy/talos/internal/app/osctl/internal/client"
+	"github.com/autonomy/talos/internal/pkg/constants"
+	"github.com/spf13/cobra"
+)
+
+// lsCmd represents the ls command
+var lsCmd = &cobra.Command{
+	Use:   "ls <path>",
+	Short: "List a directory on a node",
+	Long:  `Lists a directory in one of the roots that files can be read from: /var/log, /etc/kubernetes and /var/lib/kubelet.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			if err := cmd.Usage(); err != nil {
+				os.Exit(1)
+			}
+			os.Exit(1)
+		}
+		creds, err := client.NewDefaultClientCredentials(talosconfig)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		creds.Nodes = nodes
+		c, err := client.NewClient(constants.OsdPort, creds)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		if err := c.ListFiles(args[0]); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(lsCmd)
+}
@@ -0,0 +1,73 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/autonomy/talos/internal/app/osctl/internal/client"
+	"github.com/autonomy/talos/internal/pkg/constants"
+	"github.com/spf13/cobra"
+)
+
+var insecure bool
+
+// maintenanceCmd represents the maintenance command
+var maintenanceCmd = &cobra.Command{
+	Use:   "maintenance [status|apply <userdata>|retry]",
+	Short: "Inspect and recover a node in maintenance mode",
+	Long:  `Shows the boot failure that put the node in maintenance mode, applies corrected user data, or retries the boot sequence.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) == 0 {
+			args = []string{"status"}
+		}
+		if len(args) > 2 {
+			if err := cmd.Usage(); err != nil {
+				os.Exit(1)
+			}
+			os.Exit(1)
+		}
+		creds, err := client.NewDefaultClientCredentials(talosconfig)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		creds.InsecureSkipVerify = insecure
+		c, err := client.NewClient(constants.OsdPort, creds)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		switch args[0] {
+		case "status":
+			err = c.MaintenanceStatus()
+		case "apply":
+			if len(args) != 2 {
+				err = fmt.Errorf("the path to the user data is required")
+				break
+			}
+			var userdata []byte
+			if userdata, err = ioutil.ReadFile(args[1]); err != nil {
+				break
+			}
+			err = c.ApplyUserData(userdata)
+		case "retry":
+			err = c.RetryBoot()
+		default:
+			err = fmt.Errorf("unknown action %q", args[0])
+		}
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	maintenanceCmd.Flags().BoolVar(&insecure, "insecure", false, "skip the verification of the node certificate")
+	rootCmd.AddCommand(maintenanceCmd)
+}
@@ -0,0 +1,46 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+// nolint: dupl,golint
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/autonomy/talos/internal/app/osctl/internal/client"
+	"github.com/autonomy/talos/internal/pkg/constants"
+	"github.com/spf13/cobra"
+)
+
+var all bool
+
+// mountsCmd represents the mounts command
+var mountsCmd = &cobra.Command{
+	Use:   "mounts",
+	Short: "List mounts and their disk usage",
+	Long:  `Lists the mount points of a node with the size, usage and inode usage of their filesystems. The OS owned partitions are marked with their label in the OWNED column.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		creds, err := client.NewDefaultClientCredentials(talosconfig)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		creds.Nodes = nodes
+		c, err := client.NewClient(constants.OsdPort, creds)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		if err := c.Mounts(all); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	mountsCmd.Flags().BoolVarP(&all, "all", "a", false, "include pseudo filesystems")
+	rootCmd.AddCommand(mountsCmd)
+}
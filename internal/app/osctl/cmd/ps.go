@@ -15,22 +15,32 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var host bool
+
 // psCmd represents the processes command
 var psCmd = &cobra.Command{
 	Use:   "ps",
 	Short: "List processes",
-	Long:  ``,
+	Long:  `Lists the containers of a containerd namespace, or every process of the host as a tree.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		creds, err := client.NewDefaultClientCredentials(talosconfig)
 		if err != nil {
 			fmt.Println(err)
 			os.Exit(1)
 		}
+		creds.Nodes = nodes
 		c, err := client.NewClient(constants.OsdPort, creds)
 		if err != nil {
 			fmt.Println(err)
 			os.Exit(1)
 		}
+		if host {
+			if err := c.HostProcesses(); err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			return
+		}
 		var namespace string
 		if kubernetes {
 			namespace = criconstants.K8sContainerdNamespace
@@ -45,6 +55,7 @@ var psCmd = &cobra.Command{
 }
 
 func init() {
+	psCmd.Flags().BoolVar(&host, "host", false, "list every process of the host")
 	psCmd.Flags().BoolVarP(&kubernetes, "kubernetes", "k", false, "use the k8s.io containerd namespace")
 	rootCmd.AddCommand(psCmd)
 }
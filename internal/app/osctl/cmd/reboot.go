@@ -8,12 +8,20 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/autonomy/talos/internal/app/osctl/internal/client"
+	"github.com/autonomy/talos/internal/app/osd/proto"
 	"github.com/autonomy/talos/internal/pkg/constants"
+	"github.com/golang/protobuf/ptypes"
 	"github.com/spf13/cobra"
 )
 
+var (
+	delay time.Duration
+	force bool
+)
+
 // rebootCmd represents the reboot command
 var rebootCmd = &cobra.Command{
 	Use:   "reboot",
@@ -25,12 +33,13 @@ var rebootCmd = &cobra.Command{
 			fmt.Println(err)
 			os.Exit(1)
 		}
+		creds.Nodes = nodes
 		c, err := client.NewClient(constants.OsdPort, creds)
 		if err != nil {
 			fmt.Println(err)
 			os.Exit(1)
 		}
-		if err := c.Reboot(); err != nil {
+		if err := c.Reboot(&proto.RebootRequest{Delay: ptypes.DurationProto(delay), Force: force}); err != nil {
 			fmt.Println(err)
 			os.Exit(1)
 		}
@@ -38,5 +47,7 @@ var rebootCmd = &cobra.Command{
 }
 
 func init() {
+	rebootCmd.Flags().DurationVar(&delay, "delay", 0, "wait before running the shutdown sequence")
+	rebootCmd.Flags().BoolVar(&force, "force", false, "skip stopping the services and unmounting the partitions")
 	rootCmd.AddCommand(rebootCmd)
 }
@@ -26,6 +26,7 @@ var (
 	hours        int
 	kubernetes   bool
 	talosconfig  string
+	nodes        []string
 )
 
 // rootCmd represents the base command when called without any subcommands
@@ -50,6 +51,7 @@ func Execute() {
 		defaultTalosConfig = path.Join(u.HomeDir, ".talos", "config")
 	}
 	rootCmd.PersistentFlags().StringVar(&talosconfig, "talosconfig", defaultTalosConfig, "The path to the Talos configuration file")
+	rootCmd.PersistentFlags().StringSliceVarP(&nodes, "nodes", "n", []string{}, "The nodes that the target proxies the request to")
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Println(err)
 		os.Exit(1)
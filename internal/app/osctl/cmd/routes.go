@@ -14,24 +14,44 @@ import (
 	"github.com/spf13/cobra"
 )
 
+// The address families of the routes, as defined by Linux.
+const (
+	familyIPv4 = 2
+	familyIPv6 = 10
+)
+
+var (
+	ipv4 bool
+	ipv6 bool
+)
+
 // routesCmd represents the net routes command
 var routesCmd = &cobra.Command{
 	Use:   "routes",
 	Short: "List network routes",
-	Long:  ``,
+	Long:  `Lists the IPv4 and IPv6 routes of every routing table but the local table.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		creds, err := client.NewDefaultClientCredentials(talosconfig)
 		if err != nil {
 			fmt.Println(err)
 			os.Exit(1)
 		}
+		creds.Nodes = nodes
 		c, err := client.NewClient(constants.OsdPort, creds)
 		if err != nil {
 			fmt.Println(err)
 			os.Exit(1)
 		}
 
-		if err := c.Routes(); err != nil {
+		var family int32
+		switch {
+		case ipv4 && !ipv6:
+			family = familyIPv4
+		case ipv6 && !ipv4:
+			family = familyIPv6
+		}
+
+		if err := c.Routes(family); err != nil {
 			fmt.Println(err)
 			os.Exit(1)
 		}
@@ -39,5 +59,7 @@ var routesCmd = &cobra.Command{
 }
 
 func init() {
+	routesCmd.Flags().BoolVarP(&ipv4, "ipv4", "4", false, "list the IPv4 routes only")
+	routesCmd.Flags().BoolVarP(&ipv6, "ipv6", "6", false, "list the IPv6 routes only")
 	rootCmd.AddCommand(routesCmd)
 }
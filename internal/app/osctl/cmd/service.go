@@ -0,0 +1,65 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/autonomy/talos/internal/app/osctl/internal/client"
+	"github.com/autonomy/talos/internal/pkg/constants"
+	"github.com/spf13/cobra"
+)
+
+// serviceCmd represents the service command
+var serviceCmd = &cobra.Command{
+	Use:   "service [<id> [start|stop|restart]]",
+	Short: "Retrieve the state of system services, and control them",
+	Long:  `Lists the system services and their states, or shows the state and the event history of the specified service. The specified service can be started, stopped, or restarted.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) > 2 {
+			if err := cmd.Usage(); err != nil {
+				os.Exit(1)
+			}
+			os.Exit(1)
+		}
+		creds, err := client.NewDefaultClientCredentials(talosconfig)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		creds.Nodes = nodes
+		c, err := client.NewClient(constants.OsdPort, creds)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		switch len(args) {
+		case 0:
+			err = c.ServiceList()
+		case 1:
+			err = c.ServiceInfo(args[0])
+		default:
+			switch args[1] {
+			case "start":
+				err = c.ServiceStart(args[0])
+			case "stop":
+				err = c.ServiceStop(args[0])
+			case "restart":
+				err = c.ServiceRestart(args[0])
+			default:
+				err = fmt.Errorf("unknown action %q", args[1])
+			}
+		}
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(serviceCmd)
+}
@@ -0,0 +1,47 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+// nolint: dupl,golint
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/autonomy/talos/internal/app/osctl/internal/client"
+	"github.com/autonomy/talos/internal/app/osd/proto"
+	"github.com/autonomy/talos/internal/pkg/constants"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/spf13/cobra"
+)
+
+// shutdownCmd represents the shutdown command
+var shutdownCmd = &cobra.Command{
+	Use:   "shutdown",
+	Short: "Power off a node",
+	Long:  ``,
+	Run: func(cmd *cobra.Command, args []string) {
+		creds, err := client.NewDefaultClientCredentials(talosconfig)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		creds.Nodes = nodes
+		c, err := client.NewClient(constants.OsdPort, creds)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		if err := c.Shutdown(&proto.ShutdownRequest{Delay: ptypes.DurationProto(delay), Force: force}); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	shutdownCmd.Flags().DurationVar(&delay, "delay", 0, "wait before running the shutdown sequence")
+	shutdownCmd.Flags().BoolVar(&force, "force", false, "skip stopping the services and unmounting the partitions")
+	rootCmd.AddCommand(shutdownCmd)
+}
@@ -11,12 +11,22 @@ import (
 	"encoding/base64"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
 	"text/tabwriter"
+	"time"
 
+	"github.com/autonomy/talos/internal/app/init/pkg/boot"
 	"github.com/autonomy/talos/internal/app/osctl/internal/client/config"
 	"github.com/autonomy/talos/internal/app/osd/proto"
+	"github.com/autonomy/talos/internal/pkg/kernel/kmsg"
+	"github.com/golang/protobuf/ptypes"
 	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/golang/protobuf/ptypes/timestamp"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 )
@@ -28,13 +38,21 @@ type Credentials struct {
 	ca     []byte
 	crt    []byte
 	key    []byte
+
+	// InsecureSkipVerify disables the verification of the node certificate,
+	// for nodes in maintenance mode that have no identity.
+	InsecureSkipVerify bool
+	// Nodes are the nodes that the requests are proxied to by the target.
+	Nodes []string
 }
 
 // Client implements the proto.OSDClient interface. It serves as the
 // concrete type with the required methods.
 type Client struct {
-	conn   *grpc.ClientConn
-	client proto.OSDClient
+	conn        *grpc.ClientConn
+	client      proto.OSDClient
+	maintenance proto.MaintenanceClient
+	nodes       []string
 }
 
 // NewDefaultClientCredentials initializes ClientCredentials using default paths
@@ -71,7 +89,7 @@ func NewDefaultClientCredentials(p string) (creds *Credentials, err error) {
 func NewClient(port int, clientcreds *Credentials) (c *Client, err error) {
 	grpcOpts := []grpc.DialOption{}
 
-	c = &Client{}
+	c = &Client{nodes: clientcreds.Nodes}
 	crt, err := tls.X509KeyPair(clientcreds.crt, clientcreds.key)
 	if err != nil {
 		return nil, fmt.Errorf("could not load client key pair: %s", err)
@@ -90,16 +108,22 @@ func NewClient(port int, clientcreds *Credentials) (c *Client, err error) {
 		Certificates: []tls.Certificate{crt},
 		// Set the root certificate authorities to use the self-signed
 		// certificate.
-		RootCAs: certPool,
+		RootCAs:            certPool,
+		InsecureSkipVerify: clientcreds.InsecureSkipVerify,
 	})
 
-	grpcOpts = append(grpcOpts, grpc.WithTransportCredentials(creds))
+	grpcOpts = append(grpcOpts,
+		grpc.WithTransportCredentials(creds),
+		grpc.WithUnaryInterceptor(c.unaryInterceptor),
+		grpc.WithStreamInterceptor(c.streamInterceptor),
+	)
 	c.conn, err = grpc.Dial(fmt.Sprintf("%s:%d", clientcreds.target, port), grpcOpts...)
 	if err != nil {
 		return
 	}
 
 	c.client = proto.NewOSDClient(c.conn)
+	c.maintenance = proto.NewMaintenanceClient(c.conn)
 
 	return c, nil
 }
@@ -118,40 +142,121 @@ func (c *Client) Kubeconfig() (err error) {
 
 // Stats implements the proto.OSDClient interface.
 func (c *Client) Stats(namespace string) (err error) {
-	ctx := context.Background()
-	reply, err := c.client.Stats(ctx, &proto.StatsRequest{Namespace: namespace})
-	if err != nil {
-		return
+	replies, err := c.eachNode(func(ctx context.Context) (interface{}, error) {
+		return c.client.Stats(ctx, &proto.StatsRequest{Namespace: namespace})
+	})
+	if len(replies) == 0 {
+		return err
 	}
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
-	fmt.Fprintln(w, "NAMESPACE\tID\tMEMORY(MB)\tCPU")
-	for _, s := range reply.Stats {
-		fmt.Fprintf(w, "%s\t%s\t%.2f\t%d\n", s.Namespace, s.Id, float64(s.MemoryUsage)*1e-6, s.CpuUsage)
+	fmt.Fprintln(w, c.nodeColumn("NODE")+"NAMESPACE\tID\tMEMORY(MB)\tCPU")
+	for _, nr := range replies {
+		for _, s := range nr.reply.(*proto.StatsReply).Stats {
+			fmt.Fprintf(w, "%s%s\t%s\t%.2f\t%d\n", c.nodeColumn(nr.node), s.Namespace, s.Id, float64(s.MemoryUsage)*1e-6, s.CpuUsage)
+		}
 	}
 	if err := w.Flush(); err != nil {
 		return err
 	}
 
-	return nil
+	return err
 }
 
 // Processes implements the proto.OSDClient interface.
 func (c *Client) Processes(namespace string) (err error) {
-	ctx := context.Background()
-	reply, err := c.client.Processes(ctx, &proto.ProcessesRequest{Namespace: namespace})
-	if err != nil {
-		return
+	replies, err := c.eachNode(func(ctx context.Context) (interface{}, error) {
+		return c.client.Processes(ctx, &proto.ProcessesRequest{Namespace: namespace})
+	})
+	if len(replies) == 0 {
+		return err
 	}
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
-	fmt.Fprintln(w, "NAMESPACE\tID\tIMAGE\tPID\tSTATUS")
-	for _, p := range reply.Processes {
-		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\n", p.Namespace, p.Id, p.Image, p.Pid, p.Status)
+	fmt.Fprintln(w, c.nodeColumn("NODE")+"NAMESPACE\tID\tIMAGE\tPID\tSTATUS")
+	for _, nr := range replies {
+		for _, p := range nr.reply.(*proto.ProcessesReply).Processes {
+			fmt.Fprintf(w, "%s%s\t%s\t%s\t%d\t%s\n", c.nodeColumn(nr.node), p.Namespace, p.Id, p.Image, p.Pid, p.Status)
+		}
 	}
 	if err := w.Flush(); err != nil {
 		return err
 	}
 
-	return nil
+	return err
+}
+
+// HostProcesses implements the proto.OSDClient interface. The processes are
+// printed as a tree, the children sorted by pid under their parent.
+func (c *Client) HostProcesses() (err error) {
+	replies, err := c.eachNode(func(ctx context.Context) (interface{}, error) {
+		return c.client.HostProcesses(ctx, &empty.Empty{})
+	})
+	if len(replies) == 0 {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, c.nodeColumn("NODE")+"PID\tPPID\tSTATE\tTHREADS\tCPU-TIME\tRSS\tVSZ\tCONTAINER\tCOMMAND")
+	for _, nr := range replies {
+		processes := nr.reply.(*proto.HostProcessesReply).Processes
+
+		pids := map[int32]bool{}
+		for _, p := range processes {
+			pids[p.Pid] = true
+		}
+		children := map[int32][]*proto.HostProcess{}
+		roots := []*proto.HostProcess{}
+		for _, p := range processes {
+			if pids[p.Ppid] && p.Ppid != p.Pid {
+				children[p.Ppid] = append(children[p.Ppid], p)
+			} else {
+				roots = append(roots, p)
+			}
+		}
+
+		node := c.nodeColumn(nr.node)
+		var printTree func(p *proto.HostProcess, prefix, branch string)
+		printTree = func(p *proto.HostProcess, prefix, branch string) {
+			command := strings.Join(p.Args, " ")
+			if command == "" {
+				command = "[" + p.Command + "]"
+			}
+			var cpu time.Duration
+			if p.CpuTime != nil {
+				// nolint: errcheck
+				cpu, _ = ptypes.Duration(p.CpuTime)
+			}
+			fmt.Fprintf(w, "%s%d\t%d\t%s\t%d\t%s\t%s\t%s\t%s\t%s%s\n",
+				node, p.Pid, p.Ppid, p.State, p.Threads, cpu.Round(10*time.Millisecond),
+				formatBytes(p.ResidentMemory), formatBytes(p.VirtualMemory), p.Container,
+				prefix+branch, command,
+			)
+
+			switch branch {
+			case "├─ ":
+				prefix += "│  "
+			case "└─ ":
+				prefix += "   "
+			}
+			kids := children[p.Pid]
+			sort.Slice(kids, func(i, j int) bool { return kids[i].Pid < kids[j].Pid })
+			for i, child := range kids {
+				if i == len(kids)-1 {
+					printTree(child, prefix, "└─ ")
+				} else {
+					printTree(child, prefix, "├─ ")
+				}
+			}
+		}
+		sort.Slice(roots, func(i, j int) bool { return roots[i].Pid < roots[j].Pid })
+		for _, p := range roots {
+			printTree(p, "", "")
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	return err
 }
 
 // Restart implements the proto.OSDClient interface.
@@ -177,9 +282,9 @@ func (c *Client) Reset() (err error) {
 }
 
 // Reboot implements the proto.OSDClient interface.
-func (c *Client) Reboot() (err error) {
+func (c *Client) Reboot(r *proto.RebootRequest) (err error) {
 	ctx := context.Background()
-	_, err = c.client.Reboot(ctx, &empty.Empty{})
+	_, err = c.client.Reboot(ctx, r)
 	if err != nil {
 		return
 	}
@@ -187,70 +292,586 @@ func (c *Client) Reboot() (err error) {
 	return nil
 }
 
-// Dmesg implements the proto.OSDClient interface.
-// nolint: dupl
-func (c *Client) Dmesg() (err error) {
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-	data, err := c.client.Dmesg(ctx, &empty.Empty{})
+// Shutdown implements the proto.OSDClient interface.
+func (c *Client) Shutdown(r *proto.ShutdownRequest) (err error) {
+	ctx := context.Background()
+	_, err = c.client.Shutdown(ctx, r)
 	if err != nil {
 		return
 	}
-	fmt.Print(string(data.Bytes))
 
 	return nil
 }
 
+// BootTimeline implements the proto.OSDClient interface. If trace is not
+// empty, the timeline is written to the file in the Chrome trace event
+// format instead of being printed. When nodes are set, every node has its own
+// file.
+func (c *Client) BootTimeline(trace string) (err error) {
+	replies, err := c.eachNode(func(ctx context.Context) (interface{}, error) {
+		return c.client.BootTimeline(ctx, &empty.Empty{})
+	})
+	if len(replies) == 0 {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	if trace == "" {
+		fmt.Fprintln(w, c.nodeColumn("NODE")+"PHASE\tTASK\tSTART\tDURATION\tRESULT")
+	}
+	for _, nr := range replies {
+		timeline, err := bootTimeline(nr.reply.(*proto.BootTimelineReply))
+		if err != nil {
+			return err
+		}
+
+		if trace != "" {
+			b, err := timeline.ChromeTrace()
+			if err != nil {
+				return err
+			}
+			if err = ioutil.WriteFile(traceFile(trace, nr.node), b, 0644); err != nil {
+				return err
+			}
+			continue
+		}
+
+		var started time.Time
+		if len(timeline.Phases) > 0 {
+			started = timeline.Phases[0].Start
+		}
+		for _, phase := range timeline.Phases {
+			for _, task := range phase.Tasks {
+				duration := "-"
+				result := "Running"
+				if !task.End.IsZero() {
+					duration = task.End.Sub(task.Start).Round(time.Millisecond).String()
+					result = "OK"
+				}
+				if task.Error != "" {
+					result = "Failed: " + task.Error
+				}
+				fmt.Fprintf(w, "%s%s\t%s\t+%s\t%s\t%s\n", c.nodeColumn(nr.node), phase.Name, task.Name, task.Start.Sub(started).Round(time.Millisecond), duration, result)
+			}
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	return err
+}
+
+// traceFile returns the file that the trace of the node is written to. The
+// node is inserted before the extension, so that every node has its own file.
+func traceFile(trace, node string) string {
+	if node == "" {
+		return trace
+	}
+	ext := filepath.Ext(trace)
+
+	return strings.TrimSuffix(trace, ext) + "-" + node + ext
+}
+
+func bootTimeline(reply *proto.BootTimelineReply) (*boot.Timeline, error) {
+	timeline := &boot.Timeline{}
+	for _, phase := range reply.Phases {
+		p := &boot.PhaseRecord{Name: phase.Name}
+		var err error
+		if p.Start, p.End, err = times(phase.Start, phase.End); err != nil {
+			return nil, err
+		}
+		for _, task := range phase.Tasks {
+			t := &boot.TaskRecord{Name: task.Name, Error: task.Error}
+			if t.Start, t.End, err = times(task.Start, task.End); err != nil {
+				return nil, err
+			}
+			p.Tasks = append(p.Tasks, t)
+		}
+		timeline.Phases = append(timeline.Phases, p)
+	}
+
+	return timeline, nil
+}
+
+// times converts a start and an end timestamp. An unset end timestamp is
+// converted to the zero time.
+func times(start, end *timestamp.Timestamp) (s, e time.Time, err error) {
+	if s, err = ptypes.Timestamp(start); err != nil {
+		return s, e, err
+	}
+	if end == nil {
+		return s, e, nil
+	}
+	e, err = ptypes.Timestamp(end)
+
+	return s, e, err
+}
+
+// Dmesg implements the proto.OSDClient interface. The records of the kernel
+// log are printed with their wall clock time, facility, and priority.
+func (c *Client) Dmesg(r *proto.DmesgRequest) (err error) {
+	return c.streamEachNode(func(ctx context.Context, w io.Writer) error {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		stream, err := c.client.DmesgStream(ctx, r)
+		if err != nil {
+			return err
+		}
+		for {
+			record, err := stream.Recv()
+			if err != nil {
+				if err == io.EOF {
+					return nil
+				}
+
+				return err
+			}
+			ts, err := ptypes.Timestamp(record.Timestamp)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(w, "[%s] %-6s %-6s %s\n",
+				ts.Local().Format(time.ANSIC),
+				kmsg.FacilityName(int(record.Facility))+":",
+				kmsg.PriorityName(int(record.Priority))+":",
+				record.Message,
+			)
+		}
+	})
+}
+
 // Logs implements the proto.OSDClient interface.
 func (c *Client) Logs(r *proto.LogsRequest) (err error) {
+	return c.streamEachNode(func(ctx context.Context, w io.Writer) error {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		stream, err := c.client.Logs(ctx, r)
+		if err != nil {
+			return err
+		}
+		for {
+			data, err := stream.Recv()
+			if err != nil {
+				if err == io.EOF {
+					return nil
+				}
+
+				return err
+			}
+			if _, err = w.Write(data.Bytes); err != nil {
+				return err
+			}
+		}
+	})
+}
+
+// ListFiles implements the proto.OSDClient interface.
+func (c *Client) ListFiles(path string) (err error) {
+	replies, err := c.eachNode(func(ctx context.Context) (interface{}, error) {
+		return c.client.ListFiles(ctx, &proto.ListFilesRequest{Path: path})
+	})
+	if len(replies) == 0 {
+		return err
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, c.nodeColumn("NODE")+"MODE\tSIZE\tMODIFIED\tNAME")
+	for _, nr := range replies {
+		for _, f := range nr.reply.(*proto.ListFilesReply).Files {
+			modified := "unknown"
+			if t, err := ptypes.Timestamp(f.Modified); err == nil {
+				modified = t.Local().Format(time.Stamp)
+			}
+			name := f.Name
+			if f.Link != "" {
+				name += " -> " + f.Link
+			}
+			fmt.Fprintf(w, "%s%s\t%d\t%s\t%s\n", c.nodeColumn(nr.node), os.FileMode(f.Mode), f.Size, modified, name)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	return err
+}
+
+// ReadFile implements the proto.OSDClient interface.
+func (c *Client) ReadFile(r *proto.ReadFileRequest) (err error) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
-	stream, err := c.client.Logs(ctx, r)
+	stream, err := c.client.ReadFile(ctx, r)
 	if err != nil {
 		return
 	}
+
+	return copyData(os.Stdout, stream)
+}
+
+// CopyOut implements the proto.OSDClient interface. The tar archive is written
+// to stdout if the destination is "-", and is extracted into the destination
+// directory otherwise.
+func (c *Client) CopyOut(r *proto.CopyOutRequest, dest string) (err error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := c.client.CopyOut(ctx, r)
+	if err != nil {
+		return
+	}
+
+	if dest == "-" {
+		return copyData(os.Stdout, stream)
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		// nolint: errcheck
+		pw.CloseWithError(copyData(pw, stream))
+	}()
+
+	return extract(pr, dest)
+}
+
+// dataStream is the client side of a stream of Data messages.
+type dataStream interface {
+	Recv() (*proto.Data, error)
+}
+
+// copyData writes the chunks received from the stream to w.
+func copyData(w io.Writer, stream dataStream) error {
 	for {
 		data, err := stream.Recv()
 		if err != nil {
 			if err == io.EOF {
-				return err
+				return nil
 			}
 
 			return err
 		}
-		fmt.Print(string(data.Bytes))
+		if _, err = w.Write(data.Bytes); err != nil {
+			return err
+		}
+	}
+}
+
+// Mounts implements the proto.OSDClient interface. The OS owned mount points
+// are marked with their partition label. Pseudo filesystems, which have no
+// size, are only listed if all is set.
+func (c *Client) Mounts(all bool) (err error) {
+	replies, err := c.eachNode(func(ctx context.Context) (interface{}, error) {
+		return c.client.Mounts(ctx, &empty.Empty{})
+	})
+	if len(replies) == 0 {
+		return err
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, c.nodeColumn("NODE")+"FILESYSTEM\tTYPE\tSIZE\tUSED\tAVAILABLE\tUSE%\tINODES\tIUSE%\tMOUNTED ON\tOWNED")
+	for _, nr := range replies {
+		for _, m := range nr.reply.(*proto.MountsReply).Stats {
+			if m.Size == 0 && !all {
+				continue
+			}
+			fmt.Fprintf(w, "%s%s\t%s\t%s\t%s\t%s\t%s\t%d\t%s\t%s\t%s\n",
+				c.nodeColumn(nr.node), m.Source, m.Fstype,
+				formatBytes(m.Size), formatBytes(m.Used), formatBytes(m.Available), formatPercent(m.Used, m.Used+m.Available),
+				m.Inodes, formatPercent(m.Inodes-m.InodesFree, m.Inodes),
+				m.Target, m.Label,
+			)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
 	}
+
+	return err
 }
 
 // Version implements the proto.OSDClient interface.
 // nolint: dupl
 func (c *Client) Version() (err error) {
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-	data, err := c.client.Version(ctx, &empty.Empty{})
+	replies, err := c.eachNode(func(ctx context.Context) (interface{}, error) {
+		return c.client.Version(ctx, &empty.Empty{})
+	})
+	for _, nr := range replies {
+		if len(c.nodes) > 0 {
+			fmt.Printf("NODE: %s\n", nr.node)
+		}
+		fmt.Print(string(nr.reply.(*proto.Data).Bytes))
+	}
+
+	return err
+}
+
+// Routes implements the proto.OSDClient interface.
+func (c *Client) Routes(family int32) (err error) {
+	replies, err := c.eachNode(func(ctx context.Context) (interface{}, error) {
+		return c.client.Routes(ctx, &proto.RoutesRequest{Family: family})
+	})
+	if len(replies) == 0 {
+		return err
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, c.nodeColumn("NODE")+"INTERFACE\tDESTINATION\tGATEWAY\tSOURCE\tMETRIC\tTABLE\tPROTOCOL\tSCOPE")
+	for _, nr := range replies {
+		for _, r := range nr.reply.(*proto.RoutesReply).Routes {
+			fmt.Fprintf(w, "%s%s\t%s\t%s\t%s\t%d\t%s\t%s\t%s\n", c.nodeColumn(nr.node), r.Interface, r.Destination, r.Gateway, r.Source, r.Metric, formatName(routeTables, r.Table), formatName(routeProtocols, r.Protocol), formatName(scopes, r.Scope))
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	return err
+}
+
+// Interfaces implements the proto.OSDClient interface.
+func (c *Client) Interfaces() (err error) {
+	replies, err := c.eachNode(func(ctx context.Context) (interface{}, error) {
+		return c.client.Interfaces(ctx, &empty.Empty{})
+	})
+	if len(replies) == 0 {
+		return err
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, c.nodeColumn("NODE")+"INDEX\tNAME\tTYPE\tHWADDR\tMTU\tSTATE\tADDRESSES\tRX\tTX\tERRORS\tDROPPED")
+	for _, nr := range replies {
+		for _, i := range nr.reply.(*proto.InterfacesReply).Interfaces {
+			addresses := make([]string, 0, len(i.Addresses))
+			for _, a := range i.Addresses {
+				addresses = append(addresses, a.Address)
+			}
+			counters := i.Counters
+			if counters == nil {
+				counters = &proto.InterfaceCounters{}
+			}
+			fmt.Fprintf(w, "%s%d\t%s\t%s\t%s\t%d\t%s\t%s\t%s\t%s\t%d\t%d\n", c.nodeColumn(nr.node), i.Index, i.Name, i.Type, i.HardwareAddr, i.Mtu, i.OperState, strings.Join(addresses, ","), formatBytes(counters.RxBytes), formatBytes(counters.TxBytes), counters.RxErrors+counters.TxErrors, counters.RxDropped+counters.TxDropped)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	return err
+}
+
+// ServiceList implements the proto.OSDClient interface.
+func (c *Client) ServiceList() (err error) {
+	replies, err := c.eachNode(func(ctx context.Context) (interface{}, error) {
+		return c.client.ServiceList(ctx, &empty.Empty{})
+	})
+	if len(replies) == 0 {
+		return err
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, c.nodeColumn("NODE")+"SERVICE\tSTATE\tHEALTH\tRESTARTS\tSINCE\tMESSAGE")
+	for _, nr := range replies {
+		for _, s := range nr.reply.(*proto.ServiceListReply).Services {
+			var since, msg string
+			if len(s.Events) > 0 {
+				event := s.Events[len(s.Events)-1]
+				since = formatSince(event.Ts)
+				msg = event.Msg
+			}
+			fmt.Fprintf(w, "%s%s\t%s\t%s\t%d\t%s\t%s\n", c.nodeColumn(nr.node), s.Id, s.State, formatHealth(s.Health), s.Restarts, since, msg)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	return err
+}
+
+// ServiceInfo implements the proto.OSDClient interface.
+func (c *Client) ServiceInfo(id string) (err error) {
+	replies, err := c.eachNode(func(ctx context.Context) (interface{}, error) {
+		return c.client.ServiceInfo(ctx, &proto.ServiceInfoRequest{Id: id})
+	})
+	if len(replies) == 0 {
+		return err
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	for i, nr := range replies {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		if len(c.nodes) > 0 {
+			fmt.Fprintf(w, "NODE\t%s\n", nr.node)
+		}
+		s := nr.reply.(*proto.ServiceInfoReply).Service
+		fmt.Fprintf(w, "ID\t%s\n", s.Id)
+		fmt.Fprintf(w, "STATE\t%s\n", s.State)
+		fmt.Fprintf(w, "RESTARTS\t%d\n", s.Restarts)
+		fmt.Fprintf(w, "HEALTH\t%s\n", formatHealth(s.Health))
+		if s.Health != nil && !s.Health.Unknown {
+			fmt.Fprintf(w, "LAST HEALTH CHANGE\t%s ago\n", formatSince(s.Health.LastChange))
+			if s.Health.LastMessage != "" {
+				fmt.Fprintf(w, "LAST HEALTH MESSAGE\t%s\n", s.Health.LastMessage)
+			}
+		}
+		label := "EVENTS"
+		for _, event := range s.Events {
+			fmt.Fprintf(w, "%s\t[%s]: %s (%s ago)\n", label, event.State, event.Msg, formatSince(event.Ts))
+			label = ""
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	return err
+}
+
+// ServiceStart implements the proto.OSDClient interface.
+func (c *Client) ServiceStart(id string) (err error) {
+	ctx := context.Background()
+	_, err = c.client.ServiceStart(ctx, &proto.ServiceStartRequest{Id: id})
 	if err != nil {
 		return
 	}
-	fmt.Print(string(data.Bytes))
 
 	return nil
 }
 
-// Routes implements the proto.OSDClient interface.
-func (c *Client) Routes() (err error) {
+// ServiceStop implements the proto.OSDClient interface.
+func (c *Client) ServiceStop(id string) (err error) {
 	ctx := context.Background()
-	reply, err := c.client.Routes(ctx, &empty.Empty{})
+	_, err = c.client.ServiceStop(ctx, &proto.ServiceStopRequest{Id: id})
+	if err != nil {
+		return
+	}
+
+	return nil
+}
+
+// ServiceRestart implements the proto.OSDClient interface.
+func (c *Client) ServiceRestart(id string) (err error) {
+	ctx := context.Background()
+	_, err = c.client.ServiceRestart(ctx, &proto.ServiceRestartRequest{Id: id})
+	if err != nil {
+		return
+	}
+
+	return nil
+}
+
+// MaintenanceStatus implements the proto.MaintenanceClient interface.
+func (c *Client) MaintenanceStatus() (err error) {
+	ctx := context.Background()
+	reply, err := c.maintenance.MaintenanceStatus(ctx, &empty.Empty{})
 	if err != nil {
 		return
 	}
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
-	fmt.Fprintln(w, "INTERFACE\tDESTINATION\tGATEWAY")
-	for _, r := range reply.Routes {
-		fmt.Fprintf(w, "%s\t%s\t%s\n", r.Interface, r.Destination, r.Gateway)
+	if reply.Phase != "" {
+		fmt.Fprintf(w, "PHASE\t%s\n", reply.Phase)
+		fmt.Fprintf(w, "TASK\t%s\n", reply.Task)
 	}
+	fmt.Fprintf(w, "ERROR\t%s\n", reply.Error)
+	fmt.Fprintf(w, "SINCE\t%s ago\n", formatSince(reply.Since))
+	fmt.Fprintf(w, "AUTHENTICATED\t%t\n", reply.Authenticated)
+	fmt.Fprintf(w, "USERDATA APPLIED\t%t\n", reply.UserdataApplied)
 	if err := w.Flush(); err != nil {
 		return err
 	}
 
 	return nil
 }
+
+// ApplyUserData implements the proto.MaintenanceClient interface.
+func (c *Client) ApplyUserData(userdata []byte) (err error) {
+	ctx := context.Background()
+	_, err = c.maintenance.ApplyUserData(ctx, &proto.ApplyUserDataRequest{Userdata: userdata})
+	if err != nil {
+		return
+	}
+
+	return nil
+}
+
+// RetryBoot implements the proto.MaintenanceClient interface.
+func (c *Client) RetryBoot() (err error) {
+	ctx := context.Background()
+	_, err = c.maintenance.RetryBoot(ctx, &empty.Empty{})
+	if err != nil {
+		return
+	}
+
+	return nil
+}
+
+// formatBytes formats a size with a binary unit.
+func formatBytes(b uint64) string {
+	const unit = 1024
+	if b < unit {
+		return fmt.Sprintf("%dB", b)
+	}
+	div, exp := uint64(unit), 0
+	for n := b / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f%c", float64(b)/float64(div), "KMGTPE"[exp])
+}
+
+// formatPercent formats the ratio of used to total as a percentage.
+func formatPercent(used, total uint64) string {
+	if total == 0 {
+		return "-"
+	}
+
+	return fmt.Sprintf("%.0f%%", float64(used)*100/float64(total))
+}
+
+// The names of the routing tables, protocols and scopes, as defined by
+// rtnetlink(7).
+var (
+	routeTables = map[uint32]string{
+		253: "default",
+		254: "main",
+		255: "local",
+	}
+	routeProtocols = map[uint32]string{
+		1:  "redirect",
+		2:  "kernel",
+		3:  "boot",
+		4:  "static",
+		16: "dhcp",
+	}
+	scopes = map[uint32]string{
+		0:   "global",
+		200: "site",
+		253: "link",
+		254: "host",
+		255: "nowhere",
+	}
+)
+
+func formatName(names map[uint32]string, n uint32) string {
+	if name, ok := names[n]; ok {
+		return name
+	}
+
+	return strconv.FormatUint(uint64(n), 10)
+}
+
+func formatHealth(h *proto.ServiceHealth) string {
+	switch {
+	case h == nil || h.Unknown:
+		return "?"
+	case h.Healthy:
+		return "OK"
+	default:
+		return "Fail"
+	}
+}
+
+func formatSince(ts *timestamp.Timestamp) string {
+	t, err := ptypes.Timestamp(ts)
+	if err != nil {
+		return "unknown"
+	}
+
+	return time.Since(t).Round(time.Second).String()
+}
@@ -0,0 +1,234 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/autonomy/talos/internal/pkg/grpc/middleware/proxy"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// errMultipleNodes is returned by the commands that can only be proxied to a
+// single node.
+var errMultipleNodes = errors.New("this command does not support multiple nodes")
+
+// NodeErrors are the errors of the nodes that failed a request sent to several
+// nodes.
+type NodeErrors []error
+
+func (e NodeErrors) Error() string {
+	errs := make([]string, len(e))
+	for i, err := range e {
+		errs[i] = err.Error()
+	}
+
+	return strings.Join(errs, "\n")
+}
+
+// nodeReply is the reply of a node to a request sent to several nodes.
+type nodeReply struct {
+	node  string
+	reply interface{}
+}
+
+// eachNode sends a request to every node concurrently, through the target.
+// The replies are returned in the order of the nodes. The failure of a node
+// doesn't abort the others, and the errors are returned together. The request
+// is sent to the target itself when no nodes are set.
+func (c *Client) eachNode(fn func(ctx context.Context) (interface{}, error)) ([]*nodeReply, error) {
+	nodes := c.nodes
+	if len(nodes) == 0 {
+		nodes = []string{""}
+	}
+
+	replies := make([]*nodeReply, len(nodes))
+	errs := make([]error, len(nodes))
+
+	var wg sync.WaitGroup
+	for i, node := range nodes {
+		wg.Add(1)
+		go func(i int, node string) {
+			defer wg.Done()
+			ctx := context.Background()
+			if node != "" {
+				ctx = metadata.AppendToOutgoingContext(ctx, proxy.NodeMetadataKey, node)
+			}
+			reply, err := fn(ctx)
+			switch {
+			case err != nil && node == "":
+				errs[i] = err
+			case err != nil:
+				errs[i] = fmt.Errorf("%s: %v", node, err)
+			default:
+				replies[i] = &nodeReply{node: node, reply: reply}
+			}
+		}(i, node)
+	}
+	wg.Wait()
+
+	succeeded := []*nodeReply{}
+	for _, reply := range replies {
+		if reply != nil {
+			succeeded = append(succeeded, reply)
+		}
+	}
+
+	failed := NodeErrors{}
+	for _, err := range errs {
+		if err != nil {
+			failed = append(failed, err)
+		}
+	}
+	switch {
+	case len(failed) == 0:
+		return succeeded, nil
+	case len(nodes) == 1:
+		return succeeded, failed[0]
+	default:
+		return succeeded, failed
+	}
+}
+
+// streamEachNode opens a stream to every node concurrently, through the
+// target, and passes fn a writer that prefixes every line with the node when
+// nodes are set. Streams that follow their source may never end, so the
+// failure of a node is reported on stderr as soon as it happens when there
+// are several nodes.
+func (c *Client) streamEachNode(fn func(ctx context.Context, w io.Writer) error) error {
+	var mu sync.Mutex
+
+	_, err := c.eachNode(func(ctx context.Context) (interface{}, error) {
+		node := nodeName(ctx)
+		w := &prefixWriter{mu: &mu, w: os.Stdout}
+		if len(c.nodes) > 0 {
+			w.prefix = node + ": "
+		}
+
+		err := fn(ctx, w)
+		if flushErr := w.Flush(); err == nil {
+			err = flushErr
+		}
+		if err != nil && len(c.nodes) > 1 {
+			mu.Lock()
+			fmt.Fprintf(os.Stderr, "%s: %v\n", node, err)
+			mu.Unlock()
+		}
+
+		return nil, err
+	})
+
+	if errs, ok := err.(NodeErrors); ok {
+		return fmt.Errorf("%d of %d nodes failed", len(errs), len(c.nodes))
+	}
+
+	return err
+}
+
+// nodeName returns the node that a request sent by eachNode is routed to, or
+// an empty string if the request is sent to the target itself.
+func nodeName(ctx context.Context) string {
+	if md, ok := metadata.FromOutgoingContext(ctx); ok && len(md[proxy.NodeMetadataKey]) > 0 {
+		return md[proxy.NodeMetadataKey][0]
+	}
+
+	return ""
+}
+
+// prefixWriter writes complete lines to w, each prefixed with the prefix. The
+// lines of writers that share the mutex are not interleaved.
+type prefixWriter struct {
+	mu     *sync.Mutex
+	w      io.Writer
+	prefix string
+	buf    []byte
+}
+
+func (p *prefixWriter) Write(b []byte) (int, error) {
+	p.buf = append(p.buf, b...)
+	for {
+		i := bytes.IndexByte(p.buf, '\n')
+		if i < 0 {
+			return len(b), nil
+		}
+		if err := p.writeLine(p.buf[:i+1]); err != nil {
+			return 0, err
+		}
+		p.buf = p.buf[i+1:]
+	}
+}
+
+// Flush writes the last line if it is not terminated by a newline.
+func (p *prefixWriter) Flush() error {
+	if len(p.buf) == 0 {
+		return nil
+	}
+	line := append(p.buf, '\n')
+	p.buf = nil
+
+	return p.writeLine(line)
+}
+
+func (p *prefixWriter) writeLine(line []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	_, err := fmt.Fprintf(p.w, "%s%s", p.prefix, line)
+
+	return err
+}
+
+// nodeColumn returns the cell of the node column, which is only printed when
+// nodes are set.
+func (c *Client) nodeColumn(node string) string {
+	if len(c.nodes) == 0 {
+		return ""
+	}
+
+	return node + "\t"
+}
+
+// nodeContext routes the requests of the commands that don't send a request
+// to every node to the single node set, if any.
+func (c *Client) nodeContext(ctx context.Context) (context.Context, error) {
+	if md, ok := metadata.FromOutgoingContext(ctx); ok && len(md[proxy.NodeMetadataKey]) > 0 {
+		return ctx, nil
+	}
+
+	switch len(c.nodes) {
+	case 0:
+		return ctx, nil
+	case 1:
+		return metadata.AppendToOutgoingContext(ctx, proxy.NodeMetadataKey, c.nodes[0]), nil
+	default:
+		return nil, errMultipleNodes
+	}
+}
+
+func (c *Client) unaryInterceptor(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	ctx, err := c.nodeContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	return invoker(ctx, method, req, reply, cc, opts...)
+}
+
+func (c *Client) streamInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	ctx, err := c.nodeContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return streamer(ctx, desc, cc, method, opts...)
+}
@@ -0,0 +1,277 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/autonomy/talos/internal/app/osd/proto"
+	"github.com/golang/protobuf/ptypes"
+)
+
+// clearScreen moves the cursor home and clears the terminal.
+const clearScreen = "\033[H\033[2J"
+
+// sectorSize is the size of the sectors counted in the disk stats.
+const sectorSize = 512
+
+// topSample is a sample of the node and container stats.
+type topSample struct {
+	system     *proto.SystemStatReply
+	containers []*proto.Stat
+	at         time.Time
+}
+
+// Top renders the node and container stats at every interval, until the
+// stream is closed. The CPU usage and the rates are computed from the
+// difference between two samples, so they are shown from the second sample.
+// When nodes are set, a stream is opened to every node, and the stats of the
+// nodes are rendered one after the other.
+func (c *Client) Top(namespace string, interval time.Duration) (err error) {
+	nodes := c.nodes
+	if len(nodes) == 0 {
+		nodes = []string{""}
+	}
+
+	var mu sync.Mutex
+	screens := map[string]string{}
+	update := func(node, screen string) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		screens[node] = screen
+		rendered := make([]string, 0, len(nodes))
+		for _, node := range nodes {
+			if screen, ok := screens[node]; ok {
+				rendered = append(rendered, screen)
+			}
+		}
+		fmt.Print(clearScreen + strings.Join(rendered, "\n"))
+	}
+
+	_, err = c.eachNode(func(ctx context.Context) (interface{}, error) {
+		node := nodeName(ctx)
+		header := ""
+		if len(c.nodes) > 0 {
+			header = fmt.Sprintf("NODE: %s\n", node)
+		}
+
+		err := c.top(ctx, namespace, interval, func(screen string) {
+			update(node, header+screen)
+		})
+		if err != nil && len(c.nodes) > 1 {
+			update(node, fmt.Sprintf("%serror: %v\n", header, err))
+		}
+
+		return nil, err
+	})
+
+	return err
+}
+
+// top streams the stats of a single node, and passes every rendered sample to
+// show.
+func (c *Client) top(ctx context.Context, namespace string, interval time.Duration, show func(string)) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := c.client.SystemStat(ctx, &proto.SystemStatRequest{Interval: ptypes.DurationProto(interval)})
+	if err != nil {
+		return err
+	}
+
+	var prev *topSample
+	for {
+		reply, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		stats, err := c.client.Stats(ctx, &proto.StatsRequest{Namespace: namespace})
+		if err != nil {
+			return err
+		}
+
+		cur := &topSample{system: reply, containers: stats.Stats, at: time.Now()}
+
+		var b bytes.Buffer
+		if err = renderTop(&b, prev, cur); err != nil {
+			return err
+		}
+		show(b.String())
+
+		prev = cur
+	}
+}
+
+// nolint: gocyclo
+func renderTop(w io.Writer, prev, cur *topSample) error {
+	sys := cur.system
+
+	if load := sys.Load; load != nil {
+		fmt.Fprintf(w, "load average: %.2f, %.2f, %.2f   threads: %d running, %d total, %d blocked\n",
+			load.Load1, load.Load5, load.Load15, load.Running, load.Total, sys.ProcsBlocked)
+	}
+
+	cpu := "-"
+	if prev != nil && prev.system.Cpu != nil && sys.Cpu != nil {
+		cpu = formatCPU(prev.system.Cpu, sys.Cpu)
+	}
+	fmt.Fprintf(w, "cpu: %s\n", cpu)
+
+	if mem := sys.Memory; mem != nil {
+		used := mem.Total - mem.Available
+		fmt.Fprintf(w, "mem: %s used (%s), %s available, %s cached, %s total\n",
+			formatBytes(used), formatPercent(used, mem.Total), formatBytes(mem.Available), formatBytes(mem.Buffers+mem.Cached), formatBytes(mem.Total))
+		if mem.SwapTotal > 0 {
+			swap := mem.SwapTotal - mem.SwapFree
+			fmt.Fprintf(w, "swap: %s used (%s), %s total\n", formatBytes(swap), formatPercent(swap, mem.SwapTotal), formatBytes(mem.SwapTotal))
+		}
+	}
+
+	if len(sys.Pressure) > 0 {
+		pressure := make([]string, 0, len(sys.Pressure))
+		for _, p := range sys.Pressure {
+			s := p.Resource
+			if p.Some != nil {
+				s += fmt.Sprintf(" some %.2f%%", p.Some.Avg10)
+			}
+			if p.Full != nil {
+				s += fmt.Sprintf(" full %.2f%%", p.Full.Avg10)
+			}
+			pressure = append(pressure, s)
+		}
+		fmt.Fprintf(w, "pressure (10s): %s\n", strings.Join(pressure, ", "))
+	}
+
+	if prev != nil {
+		if err := renderDisks(w, prev.system, sys); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintln(w)
+
+	return renderContainers(w, prev, cur)
+}
+
+func renderDisks(w io.Writer, prev, cur *proto.SystemStatReply) error {
+	elapsed := elapsed(prev, cur)
+	if elapsed <= 0 {
+		return nil
+	}
+
+	previous := map[string]*proto.DiskStat{}
+	for _, disk := range prev.Disks {
+		previous[disk.Name] = disk
+	}
+
+	fmt.Fprintln(w)
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(tw, "DISK\tREAD/S\tWRITE/S\tUTIL")
+	for _, disk := range cur.Disks {
+		p, ok := previous[disk.Name]
+		if !ok || (disk.SectorsRead == p.SectorsRead && disk.SectorsWritten == p.SectorsWritten) {
+			continue
+		}
+		read := float64((disk.SectorsRead-p.SectorsRead)*sectorSize) / elapsed.Seconds()
+		written := float64((disk.SectorsWritten-p.SectorsWritten)*sectorSize) / elapsed.Seconds()
+		util := float64(disk.IoTime-p.IoTime) * 100 / float64(elapsed/time.Millisecond)
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%.0f%%\n", disk.Name, formatBytes(uint64(read)), formatBytes(uint64(written)), util)
+	}
+
+	return tw.Flush()
+}
+
+func renderContainers(w io.Writer, prev, cur *topSample) error {
+	previous := map[string]*proto.Stat{}
+	if prev != nil {
+		for _, s := range prev.containers {
+			previous[s.Namespace+"/"+s.Id] = s
+		}
+	}
+
+	type row struct {
+		stat *proto.Stat
+		cpu  float64
+	}
+	rows := make([]row, 0, len(cur.containers))
+	for _, s := range cur.containers {
+		r := row{stat: s, cpu: -1}
+		if p, ok := previous[s.Namespace+"/"+s.Id]; ok && s.CpuUsage >= p.CpuUsage {
+			if elapsed := cur.at.Sub(prev.at); elapsed > 0 {
+				r.cpu = float64(s.CpuUsage-p.CpuUsage) * 100 / float64(elapsed)
+			}
+		}
+		rows = append(rows, r)
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].cpu != rows[j].cpu {
+			return rows[i].cpu > rows[j].cpu
+		}
+		return rows[i].stat.Id < rows[j].stat.Id
+	})
+
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(tw, "NAMESPACE\tID\tMEMORY\tCPU%")
+	for _, r := range rows {
+		cpu := "-"
+		if r.cpu >= 0 {
+			cpu = fmt.Sprintf("%.1f", r.cpu)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", r.stat.Namespace, r.stat.Id, formatBytes(r.stat.MemoryUsage), cpu)
+	}
+
+	return tw.Flush()
+}
+
+// formatCPU formats the share of the CPU time spent in each mode between two
+// samples.
+func formatCPU(prev, cur *proto.CPUStat) string {
+	modes := func(c *proto.CPUStat) []uint64 {
+		// The guest time is accounted in the user time.
+		return []uint64{c.User + c.Nice, c.System + c.Irq + c.Softirq, c.Iowait, c.Steal, c.Idle}
+	}
+	p, c := modes(prev), modes(cur)
+
+	deltas := make([]float64, len(c))
+	var total float64
+	for i := range c {
+		if c[i] > p[i] {
+			deltas[i] = float64(c[i] - p[i])
+		}
+		total += deltas[i]
+	}
+	if total == 0 {
+		return "-"
+	}
+
+	return fmt.Sprintf("%.1f%% user, %.1f%% system, %.1f%% iowait, %.1f%% steal, %.1f%% idle",
+		deltas[0]*100/total, deltas[1]*100/total, deltas[2]*100/total, deltas[3]*100/total, deltas[4]*100/total)
+}
+
+func elapsed(prev, cur *proto.SystemStatReply) time.Duration {
+	p, err := ptypes.Timestamp(prev.Timestamp)
+	if err != nil {
+		return 0
+	}
+	c, err := ptypes.Timestamp(cur.Timestamp)
+	if err != nil {
+		return 0
+	}
+
+	return c.Sub(p)
+}
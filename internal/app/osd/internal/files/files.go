@@ -0,0 +1,247 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package files
+
+import (
+	"archive/tar"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Roots are the directories that files can be read from.
+var Roots = []string{
+	"/var/log",
+	"/etc/kubernetes",
+	"/var/lib/kubelet",
+}
+
+// AdminRoots are the roots that hold secrets that are not redacted, such as
+// the encryption configuration and the bootstrap token of Kubernetes. They can
+// only be read by the admin role.
+var AdminRoots = []string{
+	"/etc/kubernetes",
+}
+
+// ErrNotAllowed is returned for paths outside of the roots.
+type ErrNotAllowed struct {
+	Path string
+}
+
+func (e *ErrNotAllowed) Error() string {
+	return fmt.Sprintf("%s is not in one of the allowed roots: %s", e.Path, strings.Join(Roots, ", "))
+}
+
+// Resolve returns the absolute path of p with the symbolic links resolved,
+// and checks that it is in one of the roots.
+func Resolve(p string) (string, error) {
+	if !filepath.IsAbs(p) {
+		return "", &ErrNotAllowed{Path: p}
+	}
+
+	resolved, err := filepath.EvalSymlinks(filepath.Clean(p))
+	if err != nil {
+		return "", err
+	}
+
+	if !inRoots(resolved, Roots) {
+		return "", &ErrNotAllowed{Path: p}
+	}
+
+	return resolved, nil
+}
+
+// IsAdminOnly reports whether the resolved path is in one of the admin roots.
+func IsAdminOnly(resolved string) bool {
+	return inRoots(resolved, AdminRoots)
+}
+
+func inRoots(resolved string, roots []string) bool {
+	for _, root := range roots {
+		if resolved == root || strings.HasPrefix(resolved, root+string(filepath.Separator)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Entry is a listed file.
+type Entry struct {
+	os.FileInfo
+	// Link is the target of a symbolic link.
+	Link string
+}
+
+// List returns the entries of the directory at p. A file is listed on its own.
+func List(p string) ([]*Entry, error) {
+	resolved, err := Resolve(p)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(resolved)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []*Entry{{FileInfo: info}}, nil
+	}
+
+	infos, err := ioutil.ReadDir(resolved)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]*Entry, 0, len(infos))
+	for _, info := range infos {
+		entry := &Entry{FileInfo: info}
+		if info.Mode()&os.ModeSymlink != 0 {
+			// nolint: errcheck
+			entry.Link, _ = os.Readlink(filepath.Join(resolved, info.Name()))
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// Open opens the regular file at p for reading. The private keys are redacted
+// if requested.
+func Open(p string, redact bool) (io.ReadCloser, error) {
+	resolved, err := Resolve(p)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(resolved)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		// nolint: errcheck
+		f.Close()
+		return nil, err
+	}
+	if !info.Mode().IsRegular() {
+		// nolint: errcheck
+		f.Close()
+		return nil, fmt.Errorf("%s is not a regular file", p)
+	}
+
+	if !redact {
+		return f, nil
+	}
+
+	return struct {
+		io.Reader
+		io.Closer
+	}{NewRedactor(f), f}, nil
+}
+
+// Archive writes the file or the directory tree at p to w as a tar archive.
+// The names in the archive are relative to the parent of p. Symbolic links
+// are archived as links, and are not followed. The private keys are redacted
+// if requested.
+func Archive(ctx context.Context, p string, redact bool, w io.Writer) error {
+	resolved, err := Resolve(p)
+	if err != nil {
+		return err
+	}
+
+	tw := tar.NewWriter(w)
+	parent := filepath.Dir(resolved)
+
+	err = filepath.Walk(resolved, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		return archive(tw, parent, path, info, redact)
+	})
+	if err != nil {
+		return err
+	}
+
+	return tw.Close()
+}
+
+func archive(tw *tar.Writer, parent, path string, info os.FileInfo, redact bool) error {
+	var link string
+	if info.Mode()&os.ModeSymlink != 0 {
+		var err error
+		if link, err = os.Readlink(path); err != nil {
+			return err
+		}
+	}
+
+	header, err := tar.FileInfoHeader(info, link)
+	if err != nil {
+		// Sockets and other special files are skipped.
+		return nil
+	}
+	if header.Name, err = filepath.Rel(parent, path); err != nil {
+		return err
+	}
+	if info.IsDir() {
+		header.Name += "/"
+	}
+
+	if !info.Mode().IsRegular() {
+		return tw.WriteHeader(header)
+	}
+
+	if redact {
+		// The size of the redacted file is only known once it is read.
+		if header.Size, err = redactedSize(path); err != nil {
+			return err
+		}
+	}
+	if err = tw.WriteHeader(header); err != nil {
+		return err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	// nolint: errcheck
+	defer f.Close()
+
+	var r io.Reader = f
+	if redact {
+		r = NewRedactor(f)
+	}
+
+	// The file may grow while it is archived, for example a log, so only the
+	// size in the header is copied.
+	if _, err = io.CopyN(tw, r, header.Size); err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+
+	return nil
+}
+
+func redactedSize(path string) (int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	// nolint: errcheck
+	defer f.Close()
+
+	return io.Copy(ioutil.Discard, NewRedactor(f))
+}
@@ -0,0 +1,101 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package files
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"regexp"
+)
+
+// Redacted replaces the redacted secrets.
+const Redacted = "REDACTED"
+
+var (
+	pemBegin = regexp.MustCompile(`^-----BEGIN [A-Z ]*PRIVATE KEY-----$`)
+	pemEnd   = regexp.MustCompile(`^-----END [A-Z ]*PRIVATE KEY-----$`)
+	// keyData matches the embedded private keys of kubeconfig files.
+	keyData = regexp.MustCompile(`^(\s*client-key-data:\s*)\S+`)
+)
+
+// redactor is an io.Reader that redacts the PEM encoded private keys, and the
+// private keys embedded in kubeconfig files.
+type redactor struct {
+	r     *bufio.Reader
+	buf   []byte
+	err   error
+	inKey bool
+	// partial is set when the previous line was longer than the buffer.
+	partial bool
+	// dropping is set while the rest of a redacted line that is longer than
+	// the buffer is read.
+	dropping bool
+}
+
+// NewRedactor initializes an io.Reader that redacts the private keys read
+// from r. The secrets are redacted line by line, so that files of any size
+// are redacted in constant memory.
+func NewRedactor(r io.Reader) io.Reader {
+	return &redactor{r: bufio.NewReaderSize(r, 64*1024)}
+}
+
+// Read implements the io.Reader interface.
+func (r *redactor) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		line, err := r.r.ReadSlice('\n')
+		partial := err == bufio.ErrBufferFull
+		if partial {
+			err = nil
+		}
+		r.err = err
+		r.buf = r.redact(line)
+		r.partial = partial
+	}
+
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+
+	return n, nil
+}
+
+// redact returns the redacted copy of a line. The continuation of a line that
+// is longer than the buffer is never the start of a secret, and is dropped up
+// to the newline when the start of the line was redacted.
+func (r *redactor) redact(line []byte) []byte {
+	trimmed := bytes.TrimSpace(line)
+	newline := bytes.HasSuffix(line, []byte("\n"))
+
+	switch {
+	case r.dropping:
+		if !newline {
+			return nil
+		}
+		r.dropping = false
+		return []byte("\n")
+	case r.inKey:
+		if !r.partial && pemEnd.Match(trimmed) {
+			r.inKey = false
+			return append([]byte{}, line...)
+		}
+		return nil
+	case r.partial:
+	case pemBegin.Match(trimmed):
+		r.inKey = true
+		b := append([]byte{}, line...)
+		if !bytes.HasSuffix(b, []byte("\n")) {
+			b = append(b, '\n')
+		}
+		return append(b, Redacted+"\n"...)
+	case keyData.Match(line):
+		r.dropping = !newline
+		return keyData.ReplaceAll(line, []byte("${1}"+Redacted))
+	}
+
+	return append([]byte{}, line...)
+}
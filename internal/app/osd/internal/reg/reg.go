@@ -6,16 +6,26 @@ package reg
 
 import (
 	"context"
+	"io"
 	"io/ioutil"
 	"log"
+	"net"
 	"os"
 	"strings"
+	"time"
 
+	"github.com/autonomy/talos/internal/app/init/pkg/boot"
 	"github.com/autonomy/talos/internal/app/init/pkg/system/runner"
 	containerdrunner "github.com/autonomy/talos/internal/app/init/pkg/system/runner/containerd"
+	initproto "github.com/autonomy/talos/internal/app/init/proto"
+	"github.com/autonomy/talos/internal/app/osd/internal/files"
 	"github.com/autonomy/talos/internal/app/osd/proto"
 	filechunker "github.com/autonomy/talos/internal/pkg/chunker/file"
+	streamchunker "github.com/autonomy/talos/internal/pkg/chunker/stream"
 	"github.com/autonomy/talos/internal/pkg/constants"
+	"github.com/autonomy/talos/internal/pkg/grpc/middleware/auth/rbac"
+	"github.com/autonomy/talos/internal/pkg/kernel/kmsg"
+	"github.com/autonomy/talos/internal/pkg/proc"
 	"github.com/autonomy/talos/internal/pkg/userdata"
 	"github.com/autonomy/talos/internal/pkg/version"
 	"github.com/containerd/cgroups"
@@ -25,12 +35,15 @@ import (
 	"github.com/containerd/containerd/namespaces"
 	"github.com/containerd/containerd/oci"
 	"github.com/containerd/typeurl"
+	"github.com/golang/protobuf/ptypes"
 	"github.com/golang/protobuf/ptypes/empty"
 	specs "github.com/opencontainers/runtime-spec/specs-go"
 	"github.com/pkg/errors"
 	"github.com/vishvananda/netlink"
 	"golang.org/x/sys/unix"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // Registrator is the concrete type that implements the factory.Registrator and
@@ -189,8 +202,19 @@ func (r *Registrator) Stats(ctx context.Context, in *proto.StatsRequest) (reply
 	return reply, nil
 }
 
-// Restart implements the proto.OSDServer interface.
+// Restart implements the proto.OSDServer interface. System services in the
+// system namespace are restarted through the init API. Any other container is
+// sent SIGTERM, and is expected to be restarted by whatever is supervising it.
 func (r *Registrator) Restart(ctx context.Context, in *proto.RestartRequest) (reply *proto.RestartReply, err error) {
+	if in.Namespace == constants.SystemContainerdNamespace {
+		if _, err = r.ServiceRestart(ctx, &proto.ServiceRestartRequest{Id: in.Id}); err == nil {
+			return &proto.RestartReply{}, nil
+		}
+		if status.Code(err) != codes.NotFound {
+			return nil, err
+		}
+	}
+
 	ctx = namespaces.WithNamespace(ctx, in.Namespace)
 	client, err := containerd.New(defaults.DefaultAddress)
 	if err != nil {
@@ -214,7 +238,7 @@ func (r *Registrator) Reset(ctx context.Context, in *empty.Empty) (reply *proto.
 	// TODO(andrewrynhard): Delete all system tasks and containers.
 
 	// Set the process arguments.
-	args := runner.Args{
+	args := &runner.Args{
 		ID:          "reset",
 		ProcessArgs: []string{"/bin/kubeadm", "reset", "--force"},
 	}
@@ -246,7 +270,7 @@ func (r *Registrator) Reset(ctx context.Context, in *empty.Empty) (reply *proto.
 			oci.WithParentCgroupDevices,
 			oci.WithPrivileged,
 		),
-		runner.WithType(runner.Once),
+		runner.WithType(runner.Never),
 	)
 
 	if err != nil {
@@ -258,14 +282,56 @@ func (r *Registrator) Reset(ctx context.Context, in *empty.Empty) (reply *proto.
 	return reply, nil
 }
 
-// Reboot implements the proto.OSDServer interface.
-func (r *Registrator) Reboot(ctx context.Context, in *empty.Empty) (reply *proto.RebootReply, err error) {
+// Reboot implements the proto.OSDServer interface. The node is rebooted by
+// init, which stops the services and unmounts the partitions first.
+func (r *Registrator) Reboot(ctx context.Context, in *proto.RebootRequest) (reply *proto.RebootReply, err error) {
+	conn, client, err := newInitClient()
+	if err != nil {
+		return nil, err
+	}
 	// nolint: errcheck
-	unix.Reboot(int(unix.LINUX_REBOOT_CMD_RESTART))
+	defer conn.Close()
+
+	if _, err = client.Reboot(ctx, &initproto.RebootRequest{Delay: in.Delay, Force: in.Force}); err != nil {
+		return nil, err
+	}
 
 	reply = &proto.RebootReply{}
 
-	return
+	return reply, nil
+}
+
+// Shutdown implements the proto.OSDServer interface. The node is powered off
+// by init, which stops the services and unmounts the partitions first.
+func (r *Registrator) Shutdown(ctx context.Context, in *proto.ShutdownRequest) (reply *proto.ShutdownReply, err error) {
+	conn, client, err := newInitClient()
+	if err != nil {
+		return nil, err
+	}
+	// nolint: errcheck
+	defer conn.Close()
+
+	if _, err = client.Shutdown(ctx, &initproto.ShutdownRequest{Delay: in.Delay, Force: in.Force}); err != nil {
+		return nil, err
+	}
+
+	reply = &proto.ShutdownReply{}
+
+	return reply, nil
+}
+
+// BootTimeline implements the proto.OSDServer interface. The timeline is
+// read from the file saved by init's boot sequencer.
+func (r *Registrator) BootTimeline(ctx context.Context, in *empty.Empty) (reply *proto.BootTimelineReply, err error) {
+	timeline, err := boot.Load(constants.BootTimelinePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, status.Error(codes.NotFound, "boot timeline not found")
+		}
+		return nil, err
+	}
+
+	return timeline.Proto()
 }
 
 // Dmesg implements the proto.OSDServer interface. The klogctl syscall is used
@@ -290,10 +356,210 @@ func (r *Registrator) Dmesg(ctx context.Context, in *empty.Empty) (data *proto.D
 	return data, err
 }
 
+// minSystemStatInterval is the shortest interval at which the system stats
+// are streamed.
+const minSystemStatInterval = time.Second
+
+// SystemStat implements the proto.OSDServer interface. A sample of the system
+// stats is sent at every interval until the client cancels the stream.
+func (r *Registrator) SystemStat(req *proto.SystemStatRequest, s proto.OSD_SystemStatServer) error {
+	var interval time.Duration
+	if req.Interval != nil {
+		var err error
+		if interval, err = ptypes.Duration(req.Interval); err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+		if interval < minSystemStatInterval {
+			return status.Errorf(codes.InvalidArgument, "the interval must be at least %s", minSystemStatInterval)
+		}
+	}
+
+	if interval == 0 {
+		reply, err := systemStat()
+		if err != nil {
+			return err
+		}
+		return s.Send(reply)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		reply, err := systemStat()
+		if err != nil {
+			return err
+		}
+		if err = s.Send(reply); err != nil {
+			return err
+		}
+
+		select {
+		case <-s.Context().Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// nolint: gocyclo
+func systemStat() (reply *proto.SystemStatReply, err error) {
+	reply = &proto.SystemStatReply{
+		Timestamp: ptypes.TimestampNow(),
+	}
+
+	meminfo, err := proc.ReadMeminfo()
+	if err != nil {
+		return nil, err
+	}
+	reply.Memory = &proto.MemoryStat{
+		Total:     meminfo.MemTotal,
+		Free:      meminfo.MemFree,
+		Available: meminfo.MemAvailable,
+		Buffers:   meminfo.Buffers,
+		Cached:    meminfo.Cached,
+		Shmem:     meminfo.Shmem,
+		Slab:      meminfo.Slab,
+		Dirty:     meminfo.Dirty,
+		SwapTotal: meminfo.SwapTotal,
+		SwapFree:  meminfo.SwapFree,
+	}
+
+	stat, err := proc.ReadStat()
+	if err != nil {
+		return nil, err
+	}
+	if stat.CPU != nil {
+		reply.Cpu = cpuStat(stat.CPU)
+	}
+	for _, cpu := range stat.CPUs {
+		reply.Cpus = append(reply.Cpus, cpuStat(cpu))
+	}
+	reply.ContextSwitches = stat.ContextSwitches
+	reply.ProcsRunning = stat.ProcsRunning
+	reply.ProcsBlocked = stat.ProcsBlocked
+
+	load, err := proc.ReadLoadAvg()
+	if err != nil {
+		return nil, err
+	}
+	reply.Load = &proto.LoadAvg{
+		Load1:   load.Load1,
+		Load5:   load.Load5,
+		Load15:  load.Load15,
+		Running: load.Running,
+		Total:   load.Total,
+	}
+
+	pressure, err := proc.ReadPressure()
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range pressure {
+		reply.Pressure = append(reply.Pressure, &proto.PressureStat{
+			Resource: p.Resource,
+			Some:     pressureLine(p.Some),
+			Full:     pressureLine(p.Full),
+		})
+	}
+
+	disks, err := proc.ReadDiskStats()
+	if err != nil {
+		return nil, err
+	}
+	for _, disk := range disks {
+		reply.Disks = append(reply.Disks, &proto.DiskStat{
+			Name:            disk.Name,
+			ReadsCompleted:  disk.ReadsCompleted,
+			SectorsRead:     disk.SectorsRead,
+			ReadTime:        disk.ReadTime,
+			WritesCompleted: disk.WritesCompleted,
+			SectorsWritten:  disk.SectorsWritten,
+			WriteTime:       disk.WriteTime,
+			IoInProgress:    disk.IOInProgress,
+			IoTime:          disk.IOTime,
+		})
+	}
+
+	return reply, nil
+}
+
+func cpuStat(cpu *proc.CPUStat) *proto.CPUStat {
+	return &proto.CPUStat{
+		Name:      cpu.Name,
+		User:      cpu.User,
+		Nice:      cpu.Nice,
+		System:    cpu.System,
+		Idle:      cpu.Idle,
+		Iowait:    cpu.Iowait,
+		Irq:       cpu.IRQ,
+		Softirq:   cpu.SoftIRQ,
+		Steal:     cpu.Steal,
+		Guest:     cpu.Guest,
+		GuestNice: cpu.GuestNice,
+	}
+}
+
+func pressureLine(line *proc.PressureLine) *proto.PressureLine {
+	if line == nil {
+		return nil
+	}
+
+	return &proto.PressureLine{
+		Avg10:  line.Avg10,
+		Avg60:  line.Avg60,
+		Avg300: line.Avg300,
+		Total:  line.Total,
+	}
+}
+
+// DmesgStream implements the proto.OSDServer interface. The records of the
+// kernel log are read from /dev/kmsg, and streamed as they are parsed.
+func (r *Registrator) DmesgStream(req *proto.DmesgRequest, s proto.OSD_DmesgStreamServer) error {
+	level := len(kmsg.Priorities) - 1
+	if req.Level != "" {
+		var err error
+		if level, err = kmsg.ParsePriority(req.Level); err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+
+	records, err := kmsg.Read(s.Context(), req.Follow)
+	if err != nil {
+		return err
+	}
+
+	for record := range records {
+		if record.Priority > level {
+			continue
+		}
+
+		ts, err := ptypes.TimestampProto(record.Timestamp)
+		if err != nil {
+			return err
+		}
+
+		err = s.Send(&proto.DmesgRecord{
+			Facility:  uint32(record.Facility),
+			Priority:  uint32(record.Priority),
+			Sequence:  record.Sequence,
+			Monotonic: int64(record.Monotonic / time.Microsecond),
+			Timestamp: ts,
+			Message:   record.Message,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // Logs implements the proto.OSDServer interface. Service or container logs can
-// be requested and the contents of the log file are streamed in chunks.
+// be requested and the contents of the log file are streamed in chunks. When
+// following, the stream is closed once the container exits.
 func (r *Registrator) Logs(req *proto.LogsRequest, l proto.OSD_LogsServer) (err error) {
-	ctx := namespaces.WithNamespace(context.Background(), req.Namespace)
+	ctx := namespaces.WithNamespace(l.Context(), req.Namespace)
 	client, err := containerd.New(defaults.DefaultAddress)
 	if err != nil {
 		return err
@@ -309,7 +575,34 @@ func (r *Registrator) Logs(req *proto.LogsRequest, l proto.OSD_LogsServer) (err
 		err = _err
 		return
 	}
-	chunk := filechunker.NewChunker(file)
+
+	opts := []filechunker.Option{
+		filechunker.Follow(req.Follow),
+		filechunker.TailLines(int(req.TailLines)),
+	}
+	if req.Since != nil {
+		since, _err := ptypes.Timestamp(req.Since)
+		if _err != nil {
+			err = _err
+			return
+		}
+		if err = checkTimestamps(file, req.Id); err != nil {
+			// nolint: errcheck
+			file.Close()
+			return err
+		}
+		opts = append(opts, filechunker.Since(since))
+	}
+	if req.Follow {
+		exitC, _err := taskExit(ctx, client, req.Id)
+		if _err != nil {
+			err = _err
+			return
+		}
+		opts = append(opts, filechunker.Until(exitC))
+	}
+
+	chunk := filechunker.NewChunker(file, opts...)
 
 	if chunk == nil {
 		return errors.New("no log reader found")
@@ -321,45 +614,513 @@ func (r *Registrator) Logs(req *proto.LogsRequest, l proto.OSD_LogsServer) (err
 		}
 	}
 
+	return chunk.Err()
+}
+
+// checkTimestamps returns a FailedPrecondition error if the log does not
+// contain timestamps. The lines are timestamped as they are captured, but
+// the logs written by an earlier version are not, and cannot be filtered by
+// time.
+func checkTimestamps(file *os.File, id string) error {
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	if info.Size() == 0 {
+		return nil
+	}
+	ok, err := filechunker.HasTimestamps(file, info.Size())
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return status.Errorf(codes.FailedPrecondition, "the log of %q has no timestamps, and cannot be filtered by time", id)
+	}
+
 	return nil
 }
 
-// Routes implements the proto.OSDServer interface.
-func (r *Registrator) Routes(ctx context.Context, in *empty.Empty) (data *proto.RoutesReply, err error) {
-	routeList, err := netlink.RouteList(nil, 2)
+// taskExit returns a channel that is closed once the task of the container
+// exits.
+func taskExit(ctx context.Context, client *containerd.Client, id string) (<-chan struct{}, error) {
+	container, err := client.LoadContainer(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	task, err := container.Task(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	statusC, err := task.Wait(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	routes := []*proto.Route{}
+	exitC := make(chan struct{})
+	go func() {
+		select {
+		case <-statusC:
+			close(exitC)
+		case <-ctx.Done():
+		}
+	}()
 
-	for _, route := range routeList {
-		link, _err := netlink.LinkByIndex(route.LinkIndex)
-		if _err != nil {
-			err = _err
+	return exitC, nil
+}
+
+// ListFiles implements the proto.OSDServer interface. Only the directories in
+// the allowed roots can be listed.
+func (r *Registrator) ListFiles(ctx context.Context, in *proto.ListFilesRequest) (reply *proto.ListFilesReply, err error) {
+	if err = authorizeFile(ctx, in.Path, false); err != nil {
+		return nil, err
+	}
+	entries, err := files.List(in.Path)
+	if err != nil {
+		return nil, fileError(err)
+	}
+
+	reply = &proto.ListFilesReply{}
+	for _, entry := range entries {
+		file := &proto.FileInfo{
+			Name: entry.Name(),
+			Size: entry.Size(),
+			Mode: uint32(entry.Mode()),
+			Link: entry.Link,
+		}
+		if file.Modified, err = ptypes.TimestampProto(entry.ModTime()); err != nil {
 			return nil, err
 		}
+		reply.Files = append(reply.Files, file)
+	}
+
+	return reply, nil
+}
 
-		destination := "0.0.0.0"
-		if route.Dst != nil {
-			destination = route.Dst.String()
+// ReadFile implements the proto.OSDServer interface. The contents of a file in
+// the allowed roots are streamed in chunks.
+func (r *Registrator) ReadFile(req *proto.ReadFileRequest, s proto.OSD_ReadFileServer) (err error) {
+	if err = authorizeFile(s.Context(), req.Path, req.NoRedact); err != nil {
+		return err
+	}
+
+	f, err := files.Open(req.Path, !req.NoRedact)
+	if err != nil {
+		return fileError(err)
+	}
+
+	chunk := streamchunker.NewChunker(f, streamchunker.Size(64*1024))
+	for data := range chunk.Read(s.Context()) {
+		if err = s.Send(&proto.Data{Bytes: data}); err != nil {
+			return
 		}
+	}
+
+	return chunk.Err()
+}
+
+// CopyOut implements the proto.OSDServer interface. A file or a directory in
+// the allowed roots is streamed in chunks as a tar archive.
+func (r *Registrator) CopyOut(req *proto.CopyOutRequest, s proto.OSD_CopyOutServer) (err error) {
+	if err = authorizeFile(s.Context(), req.Path, req.NoRedact); err != nil {
+		return err
+	}
 
-		gateway := "0.0.0.0"
-		if route.Gw != nil {
-			gateway = route.Gw.String()
+	pr, pw := io.Pipe()
+	errCh := make(chan error, 1)
+	go func() {
+		err := files.Archive(s.Context(), req.Path, !req.NoRedact, pw)
+		errCh <- err
+		// nolint: errcheck
+		pw.CloseWithError(err)
+	}()
+
+	chunk := streamchunker.NewChunker(pr, streamchunker.Size(64*1024))
+	for data := range chunk.Read(s.Context()) {
+		if err = s.Send(&proto.Data{Bytes: data}); err != nil {
+			return
 		}
+	}
+
+	return <-errCh
+}
 
-		routeMessage := &proto.Route{
-			Interface:   link.Attrs().Name,
-			Destination: destination,
-			Gateway:     gateway,
+// authorizeFile checks that the path is in one of the allowed roots, and
+// denies the reads of the admin roots, and the reads without redaction, to
+// the clients without the admin role.
+func authorizeFile(ctx context.Context, p string, noRedact bool) error {
+	resolved, err := files.Resolve(p)
+	if err != nil {
+		return fileError(err)
+	}
+	if role, ok := rbac.FromContext(ctx); ok && role >= rbac.Admin {
+		return nil
+	}
+	if noRedact {
+		return status.Error(codes.PermissionDenied, "reading files without redaction requires the admin role")
+	}
+	if files.IsAdminOnly(resolved) {
+		return status.Errorf(codes.PermissionDenied, "reading %s requires the admin role", p)
+	}
+
+	return nil
+}
+
+// fileError converts the errors of the files package to gRPC errors.
+func fileError(err error) error {
+	switch {
+	case os.IsNotExist(err):
+		return status.Error(codes.NotFound, err.Error())
+	case os.IsPermission(err):
+		return status.Error(codes.PermissionDenied, err.Error())
+	}
+	if _, ok := err.(*files.ErrNotAllowed); ok {
+		return status.Error(codes.PermissionDenied, err.Error())
+	}
+
+	return err
+}
+
+// Mounts implements the proto.OSDServer interface. The mount points of the
+// host are retrieved from the init API, since osd runs in its own mount
+// namespace.
+func (r *Registrator) Mounts(ctx context.Context, in *empty.Empty) (reply *proto.MountsReply, err error) {
+	conn, client, err := newInitClient()
+	if err != nil {
+		return nil, err
+	}
+	// nolint: errcheck
+	defer conn.Close()
+
+	initReply, err := client.Mounts(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+
+	reply = &proto.MountsReply{}
+	for _, stat := range initReply.Stats {
+		reply.Stats = append(reply.Stats, &proto.MountStat{
+			Source:     stat.Source,
+			Target:     stat.Target,
+			Fstype:     stat.Fstype,
+			Options:    stat.Options,
+			Size:       stat.Size,
+			Used:       stat.Used,
+			Available:  stat.Available,
+			Inodes:     stat.Inodes,
+			InodesFree: stat.InodesFree,
+			Owned:      stat.Owned,
+			Label:      stat.Label,
+		})
+	}
+
+	return reply, nil
+}
+
+// HostProcesses implements the proto.OSDServer interface. The processes are
+// retrieved from the init API, since osd runs in its own PID namespace, and
+// are matched to the containerd containers by their cgroup.
+func (r *Registrator) HostProcesses(ctx context.Context, in *empty.Empty) (reply *proto.HostProcessesReply, err error) {
+	conn, client, err := newInitClient()
+	if err != nil {
+		return nil, err
+	}
+	// nolint: errcheck
+	defer conn.Close()
+
+	initReply, err := client.HostProcesses(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+
+	containers := containerCgroups(ctx)
+
+	reply = &proto.HostProcessesReply{}
+	for _, p := range initReply.Processes {
+		reply.Processes = append(reply.Processes, &proto.HostProcess{
+			Pid:            p.Pid,
+			Ppid:           p.Ppid,
+			State:          p.State,
+			Command:        p.Command,
+			Args:           p.Args,
+			Executable:     p.Executable,
+			ResidentMemory: p.ResidentMemory,
+			VirtualMemory:  p.VirtualMemory,
+			CpuTime:        p.CpuTime,
+			Threads:        p.Threads,
+			Cgroup:         p.Cgroup,
+			Container:      containers[p.Cgroup],
+		})
+	}
+
+	return reply, nil
+}
+
+// containerCgroups returns the containers of every containerd namespace as
+// namespace/id, keyed by their cgroup. The containers that can't be
+// inspected are skipped.
+func containerCgroups(ctx context.Context) map[string]string {
+	cgroups := map[string]string{}
+
+	client, err := containerd.New(defaults.DefaultAddress)
+	if err != nil {
+		log.Println(err)
+		return cgroups
+	}
+	// nolint: errcheck
+	defer client.Close()
+
+	nss, err := client.NamespaceService().List(ctx)
+	if err != nil {
+		log.Println(err)
+		return cgroups
+	}
+
+	for _, ns := range nss {
+		nsctx := namespaces.WithNamespace(ctx, ns)
+		containers, err := client.Containers(nsctx)
+		if err != nil {
+			log.Println(err)
+			continue
+		}
+		for _, container := range containers {
+			spec, err := container.Spec(nsctx)
+			if err != nil {
+				log.Println(err)
+				continue
+			}
+			if spec.Linux != nil && spec.Linux.CgroupsPath != "" {
+				cgroups[spec.Linux.CgroupsPath] = ns + "/" + container.ID()
+			}
+		}
+	}
+
+	return cgroups
+}
+
+// Routes implements the proto.OSDServer interface. The routes of every table
+// but the local table are returned.
+func (r *Registrator) Routes(ctx context.Context, in *proto.RoutesRequest) (data *proto.RoutesReply, err error) {
+	var families []int
+	switch in.Family {
+	case 0:
+		families = []int{netlink.FAMILY_V4, netlink.FAMILY_V6}
+	case netlink.FAMILY_V4, netlink.FAMILY_V6:
+		families = []int{int(in.Family)}
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "unsupported address family %d", in.Family)
+	}
+
+	links := map[int]string{}
+	routes := []*proto.Route{}
+
+	for _, family := range families {
+		routeList, err := netlink.RouteListFiltered(family, &netlink.Route{Table: unix.RT_TABLE_UNSPEC}, netlink.RT_FILTER_TABLE)
+		if err != nil {
+			return nil, err
+		}
+
+		unspecified := net.IPv4zero
+		if family == netlink.FAMILY_V6 {
+			unspecified = net.IPv6unspecified
+		}
+
+		for _, route := range routeList {
+			if route.Table == unix.RT_TABLE_LOCAL {
+				continue
+			}
+
+			name, ok := links[route.LinkIndex]
+			if !ok && route.LinkIndex != 0 {
+				link, err := netlink.LinkByIndex(route.LinkIndex)
+				if err != nil {
+					return nil, err
+				}
+				name = link.Attrs().Name
+				links[route.LinkIndex] = name
+			}
+
+			destination := unspecified.String()
+			if route.Dst != nil {
+				destination = route.Dst.String()
+			}
+
+			gateway := unspecified.String()
+			if route.Gw != nil {
+				gateway = route.Gw.String()
+			}
+
+			var source string
+			if route.Src != nil {
+				source = route.Src.String()
+			}
+
+			routes = append(routes, &proto.Route{
+				Interface:   name,
+				Destination: destination,
+				Gateway:     gateway,
+				Family:      int32(family),
+				Metric:      uint32(route.Priority),
+				Source:      source,
+				Table:       uint32(route.Table),
+				Protocol:    uint32(route.Protocol),
+				Scope:       uint32(route.Scope),
+			})
 		}
-		routes = append(routes, routeMessage)
 	}
 
 	data = &proto.RoutesReply{Routes: routes}
-	return data, err
+	return data, nil
+}
+
+// Interfaces implements the proto.OSDServer interface.
+func (r *Registrator) Interfaces(ctx context.Context, in *empty.Empty) (reply *proto.InterfacesReply, err error) {
+	links, err := netlink.LinkList()
+	if err != nil {
+		return nil, err
+	}
+
+	reply = &proto.InterfacesReply{}
+	for _, link := range links {
+		attrs := link.Attrs()
+		iface := &proto.Interface{
+			Index:        uint32(attrs.Index),
+			Name:         attrs.Name,
+			Type:         link.Type(),
+			HardwareAddr: attrs.HardwareAddr.String(),
+			Mtu:          uint32(attrs.MTU),
+			OperState:    attrs.OperState.String(),
+		}
+		if attrs.Flags != 0 {
+			iface.Flags = strings.Split(attrs.Flags.String(), "|")
+		}
+
+		addrs, err := netlink.AddrList(link, netlink.FAMILY_ALL)
+		if err != nil {
+			return nil, err
+		}
+		for _, addr := range addrs {
+			family := int32(netlink.FAMILY_V6)
+			if addr.IP.To4() != nil {
+				family = netlink.FAMILY_V4
+			}
+			iface.Addresses = append(iface.Addresses, &proto.InterfaceAddress{
+				Address: addr.IPNet.String(),
+				Family:  family,
+				Scope:   uint32(addr.Scope),
+			})
+		}
+
+		if stats := attrs.Statistics; stats != nil {
+			iface.Counters = &proto.InterfaceCounters{
+				RxBytes:   stats.RxBytes,
+				RxPackets: stats.RxPackets,
+				RxErrors:  stats.RxErrors,
+				RxDropped: stats.RxDropped,
+				TxBytes:   stats.TxBytes,
+				TxPackets: stats.TxPackets,
+				TxErrors:  stats.TxErrors,
+				TxDropped: stats.TxDropped,
+			}
+		}
+
+		reply.Interfaces = append(reply.Interfaces, iface)
+	}
+
+	return reply, nil
+}
+
+// ServiceList implements the proto.OSDServer interface. The state of the
+// system services is retrieved from the init API.
+func (r *Registrator) ServiceList(ctx context.Context, in *empty.Empty) (reply *proto.ServiceListReply, err error) {
+	conn, client, err := newInitClient()
+	if err != nil {
+		return nil, err
+	}
+	// nolint: errcheck
+	defer conn.Close()
+
+	initReply, err := client.ServiceList(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+
+	reply = &proto.ServiceListReply{}
+	for _, service := range initReply.Services {
+		reply.Services = append(reply.Services, serviceInfo(service))
+	}
+
+	return reply, nil
+}
+
+// ServiceInfo implements the proto.OSDServer interface. The state of the
+// system service is retrieved from the init API.
+func (r *Registrator) ServiceInfo(ctx context.Context, in *proto.ServiceInfoRequest) (reply *proto.ServiceInfoReply, err error) {
+	conn, client, err := newInitClient()
+	if err != nil {
+		return nil, err
+	}
+	// nolint: errcheck
+	defer conn.Close()
+
+	initReply, err := client.ServiceInfo(ctx, &initproto.ServiceInfoRequest{Id: in.Id})
+	if err != nil {
+		return nil, err
+	}
+
+	reply = &proto.ServiceInfoReply{Service: serviceInfo(initReply.Service)}
+
+	return reply, nil
+}
+
+// ServiceStart implements the proto.OSDServer interface. The system service
+// is started through the init API.
+func (r *Registrator) ServiceStart(ctx context.Context, in *proto.ServiceStartRequest) (reply *proto.ServiceStartReply, err error) {
+	conn, client, err := newInitClient()
+	if err != nil {
+		return nil, err
+	}
+	// nolint: errcheck
+	defer conn.Close()
+
+	if _, err = client.ServiceStart(ctx, &initproto.ServiceStartRequest{Id: in.Id}); err != nil {
+		return nil, err
+	}
+
+	return &proto.ServiceStartReply{}, nil
+}
+
+// ServiceStop implements the proto.OSDServer interface. The system service
+// is stopped through the init API.
+func (r *Registrator) ServiceStop(ctx context.Context, in *proto.ServiceStopRequest) (reply *proto.ServiceStopReply, err error) {
+	conn, client, err := newInitClient()
+	if err != nil {
+		return nil, err
+	}
+	// nolint: errcheck
+	defer conn.Close()
+
+	if _, err = client.ServiceStop(ctx, &initproto.ServiceStopRequest{Id: in.Id}); err != nil {
+		return nil, err
+	}
+
+	return &proto.ServiceStopReply{}, nil
+}
+
+// ServiceRestart implements the proto.OSDServer interface. The system
+// service is restarted through the init API.
+func (r *Registrator) ServiceRestart(ctx context.Context, in *proto.ServiceRestartRequest) (reply *proto.ServiceRestartReply, err error) {
+	conn, client, err := newInitClient()
+	if err != nil {
+		return nil, err
+	}
+	// nolint: errcheck
+	defer conn.Close()
+
+	if _, err = client.ServiceRestart(ctx, &initproto.ServiceRestartRequest{Id: in.Id}); err != nil {
+		return nil, err
+	}
+
+	return &proto.ServiceRestartReply{}, nil
 }
 
 // Version implements the proto.OSDServer interface.
@@ -373,3 +1134,46 @@ func (r *Registrator) Version(ctx context.Context, in *empty.Empty) (data *proto
 
 	return data, err
 }
+
+// newInitClient dials the init API on its unix socket.
+func newInitClient() (conn *grpc.ClientConn, client initproto.InitClient, err error) {
+	conn, err = grpc.Dial(
+		constants.InitSocketPath,
+		grpc.WithInsecure(),
+		grpc.WithDialer(func(address string, timeout time.Duration) (net.Conn, error) {
+			return net.DialTimeout("unix", address, timeout)
+		}),
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return conn, initproto.NewInitClient(conn), nil
+}
+
+func serviceInfo(in *initproto.ServiceInfo) *proto.ServiceInfo {
+	info := &proto.ServiceInfo{
+		Id:       in.Id,
+		State:    in.State,
+		Restarts: in.Restarts,
+	}
+
+	for _, event := range in.Events {
+		info.Events = append(info.Events, &proto.ServiceEvent{
+			Msg:   event.Msg,
+			State: event.State,
+			Ts:    event.Ts,
+		})
+	}
+
+	if in.Health != nil {
+		info.Health = &proto.ServiceHealth{
+			Unknown:     in.Health.Unknown,
+			Healthy:     in.Health.Healthy,
+			LastMessage: in.Health.LastMessage,
+			LastChange:  in.Health.LastChange,
+		}
+	}
+
+	return info
+}
@@ -9,8 +9,13 @@ import (
 	"log"
 
 	"github.com/autonomy/talos/internal/app/osd/internal/reg"
+	"github.com/autonomy/talos/internal/app/osd/pkg/rules"
+	"github.com/autonomy/talos/internal/app/osd/proto"
 	"github.com/autonomy/talos/internal/pkg/constants"
 	"github.com/autonomy/talos/internal/pkg/grpc/factory"
+	"github.com/autonomy/talos/internal/pkg/grpc/middleware/auth/rbac"
+	"github.com/autonomy/talos/internal/pkg/grpc/middleware/chain"
+	"github.com/autonomy/talos/internal/pkg/grpc/middleware/proxy"
 	"github.com/autonomy/talos/internal/pkg/grpc/tls"
 	"github.com/autonomy/talos/internal/pkg/userdata"
 	"google.golang.org/grpc"
@@ -38,6 +43,21 @@ func main() {
 		log.Fatalf("credentials: %v", err)
 	}
 
+	// The requests are authorized before those that name another node are
+	// proxied to it with the identity of this node, and the role of the
+	// client.
+	a := &rbac.Authorizer{Rules: rules.Rules}
+	p := &proxy.Proxy{
+		Port: constants.OsdPort,
+		DialOptions: []grpc.DialOption{
+			grpc.WithTransportCredentials(credentials.NewTLS(config)),
+		},
+		NewClient: func(conn *grpc.ClientConn) interface{} {
+			return proto.NewOSDClient(conn)
+		},
+		Metadata: rbac.Metadata,
+	}
+
 	log.Println("Starting osd")
 	err = factory.Listen(
 		&reg.Registrator{Data: data},
@@ -46,6 +66,8 @@ func main() {
 			grpc.Creds(
 				credentials.NewTLS(config),
 			),
+			grpc.UnaryInterceptor(chain.UnaryInterceptor(a.UnaryInterceptor, p.UnaryInterceptor)),
+			grpc.StreamInterceptor(chain.StreamInterceptor(a.StreamInterceptor, p.StreamInterceptor)),
 		),
 	)
 	if err != nil {
@@ -0,0 +1,44 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package rules
+
+import (
+	"github.com/autonomy/talos/internal/pkg/grpc/middleware/auth/rbac"
+)
+
+// Rules are the roles required by the methods of the OSD and Maintenance
+// services. Reading files without redacting them additionally requires the
+// Admin role.
+var Rules = map[string]rbac.Role{
+	"/proto.OSD/BootTimeline":   rbac.ReadOnly,
+	"/proto.OSD/CopyOut":        rbac.ReadOnly,
+	"/proto.OSD/Dmesg":          rbac.ReadOnly,
+	"/proto.OSD/DmesgStream":    rbac.ReadOnly,
+	"/proto.OSD/HostProcesses":  rbac.ReadOnly,
+	"/proto.OSD/Interfaces":     rbac.ReadOnly,
+	"/proto.OSD/Kubeconfig":     rbac.Admin,
+	"/proto.OSD/ListFiles":      rbac.ReadOnly,
+	"/proto.OSD/Logs":           rbac.ReadOnly,
+	"/proto.OSD/Mounts":         rbac.ReadOnly,
+	"/proto.OSD/Processes":      rbac.ReadOnly,
+	"/proto.OSD/ReadFile":       rbac.ReadOnly,
+	"/proto.OSD/Reboot":         rbac.Admin,
+	"/proto.OSD/Reset":          rbac.Admin,
+	"/proto.OSD/Restart":        rbac.Operator,
+	"/proto.OSD/Routes":         rbac.ReadOnly,
+	"/proto.OSD/ServiceInfo":    rbac.ReadOnly,
+	"/proto.OSD/ServiceList":    rbac.ReadOnly,
+	"/proto.OSD/ServiceRestart": rbac.Operator,
+	"/proto.OSD/ServiceStart":   rbac.Operator,
+	"/proto.OSD/ServiceStop":    rbac.Operator,
+	"/proto.OSD/Shutdown":       rbac.Admin,
+	"/proto.OSD/Stats":          rbac.ReadOnly,
+	"/proto.OSD/SystemStat":     rbac.ReadOnly,
+	"/proto.OSD/Version":        rbac.ReadOnly,
+
+	"/proto.Maintenance/MaintenanceStatus": rbac.ReadOnly,
+	"/proto.Maintenance/ApplyUserData":     rbac.Admin,
+	"/proto.Maintenance/RetryBoot":         rbac.Admin,
+}
@@ -0,0 +1,31 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package rules
+
+import (
+	"testing"
+
+	"github.com/autonomy/talos/internal/app/osd/proto"
+	"google.golang.org/grpc"
+)
+
+type server struct {
+	proto.OSDServer
+	proto.MaintenanceServer
+}
+
+func TestRules(t *testing.T) {
+	s := grpc.NewServer()
+	proto.RegisterOSDServer(s, &server{})
+	proto.RegisterMaintenanceServer(s, &server{})
+
+	for name, info := range s.GetServiceInfo() {
+		for _, method := range info.Methods {
+			if _, ok := Rules["/"+name+"/"+method.Name]; !ok {
+				t.Errorf("no rule for /%s/%s", name, method.Name)
+			}
+		}
+	}
+}
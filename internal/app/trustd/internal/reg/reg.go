@@ -6,15 +6,22 @@ package reg
 
 import (
 	"context"
+	stdlibx509 "crypto/x509"
+	"encoding/pem"
 	"io/ioutil"
 	"log"
 	"os"
 	"path"
 
 	"github.com/autonomy/talos/internal/app/trustd/proto"
+	"github.com/autonomy/talos/internal/pkg/constants"
 	"github.com/autonomy/talos/internal/pkg/crypto/x509"
+	"github.com/autonomy/talos/internal/pkg/net"
 	"github.com/autonomy/talos/internal/pkg/userdata"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
 )
 
 // Registrator is the concrete type that implements the factory.Registrator and
@@ -28,11 +35,14 @@ func (r *Registrator) Register(s *grpc.Server) {
 	proto.RegisterTrustdServer(s, r)
 }
 
-// Certificate implements the proto.TrustdServer interface.
+// Certificate implements the proto.TrustdServer interface. The credentials of
+// trustd are shared by every node, so the certificates that it issues only
+// allow a node to forward the read-only role of a client.
 func (r *Registrator) Certificate(ctx context.Context, in *proto.CertificateRequest) (resp *proto.CertificateResponse, err error) {
-	// TODO: Verify that the request is coming from the IP addresss declared in
-	// the CSR.
-	signed, err := x509.NewCertificateFromCSRBytes(r.Data.CA.Crt, r.Data.CA.Key, in.Csr)
+	if err = verifyPeer(ctx, in.Csr); err != nil {
+		return nil, err
+	}
+	signed, err := x509.NewCertificateFromCSRBytes(r.Data.CA.Crt, r.Data.CA.Key, in.Csr, x509.Organization(constants.NodeOrganization))
 	if err != nil {
 		return
 	}
@@ -45,6 +55,31 @@ func (r *Registrator) Certificate(ctx context.Context, in *proto.CertificateRequ
 	return resp, nil
 }
 
+// verifyPeer checks that the request is coming from one of the IP addresses
+// declared in the CSR, so that a node certificate is only issued to the node
+// that it identifies.
+func verifyPeer(ctx context.Context, csr []byte) error {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return status.Error(codes.Unauthenticated, "no peer")
+	}
+	ip := net.AddrIP(p.Addr)
+
+	block, _ := pem.Decode(csr)
+	if block == nil {
+		return status.Error(codes.InvalidArgument, "failed to decode the CSR")
+	}
+	request, err := stdlibx509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "failed to parse the CSR: %v", err)
+	}
+	if !net.ContainsIP(request.IPAddresses, ip) {
+		return status.Errorf(codes.PermissionDenied, "the CSR does not declare the peer address %s", ip)
+	}
+
+	return nil
+}
+
 // WriteFile implements the proto.TrustdServer interface.
 func (r *Registrator) WriteFile(ctx context.Context, in *proto.WriteFileRequest) (resp *proto.WriteFileResponse, err error) {
 	if err = os.MkdirAll(path.Dir(in.Path), os.ModeDir); err != nil {
@@ -8,13 +8,19 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"time"
 
 	"github.com/autonomy/talos/internal/pkg/chunker"
 )
 
 // Options is the functional options struct.
 type Options struct {
-	Size int
+	Size         int
+	Follow       bool
+	Until        <-chan struct{}
+	PollInterval time.Duration
+	TailLines    int
+	Since        time.Time
 }
 
 // Option is the functional option func.
@@ -27,12 +33,55 @@ func Size(s int) Option {
 	}
 }
 
+// Follow sets whether the Chunker waits for data to be appended to the file
+// once it reaches the end of it.
+func Follow(f bool) Option {
+	return func(args *Options) {
+		args.Follow = f
+	}
+}
+
+// Until stops following the file once the channel is closed. The Chunker
+// reads the file to the end before it stops.
+func Until(c <-chan struct{}) Option {
+	return func(args *Options) {
+		args.Until = c
+	}
+}
+
+// PollInterval sets how often the Chunker checks for appended data while
+// following the file.
+func PollInterval(d time.Duration) Option {
+	return func(args *Options) {
+		args.PollInterval = d
+	}
+}
+
+// TailLines starts the Chunker at the last n lines of the file. If n is zero,
+// the Chunker starts at the beginning of the file.
+func TailLines(n int) Option {
+	return func(args *Options) {
+		args.TailLines = n
+	}
+}
+
+// Since starts the Chunker at the first line with a timestamp at or after t.
+// See SinceOffset for the timestamps that are recognized.
+func Since(t time.Time) Option {
+	return func(args *Options) {
+		args.Since = t
+	}
+}
+
 // File is a conecrete type that implements the chunker.Chunker interface.
 type File struct {
 	source  Source
 	options *Options
+	err     error
 }
 
+var _ chunker.Chunker = (*File)(nil)
+
 // Source is an interface describing the source of a File.
 type Source interface {
 	io.ReaderAt
@@ -42,9 +91,10 @@ type Source interface {
 }
 
 // NewChunker initializes a Chunker with default values.
-func NewChunker(source Source, setters ...Option) chunker.Chunker {
+func NewChunker(source Source, setters ...Option) *File {
 	opts := &Options{
-		Size: 1024,
+		Size:         1024,
+		PollInterval: 250 * time.Millisecond,
 	}
 
 	for _, setter := range setters {
@@ -52,12 +102,13 @@ func NewChunker(source Source, setters ...Option) chunker.Chunker {
 	}
 
 	return &File{
-		source,
-		opts,
+		source:  source,
+		options: opts,
 	}
 }
 
 // Read implements ChunkReader.
+// nolint: gocyclo
 func (c *File) Read(ctx context.Context) <-chan []byte {
 	// Create a buffered channel of length 1.
 	ch := make(chan []byte, 1)
@@ -67,37 +118,85 @@ func (c *File) Read(ctx context.Context) <-chan []byte {
 		// nolint: errcheck
 		defer c.source.Close()
 
-		offset, err := c.source.Seek(0, io.SeekStart)
+		offset, err := c.start()
 		if err != nil {
+			c.err = fmt.Errorf("seek error: %v", err)
 			return
 		}
+		follow := c.options.Follow
 		buf := make([]byte, c.options.Size)
 		for {
-			select {
-			case <-ctx.Done():
+			n, err := c.source.ReadAt(buf, offset)
+			if err != nil && err != io.EOF {
+				c.err = fmt.Errorf("read error: %v", err)
 				return
-			default:
-				n, err := c.source.ReadAt(buf, offset)
-				if err != nil {
-					if err != io.EOF {
-						fmt.Printf("read error: %s\n", err.Error())
-						break
-					}
-				}
+			}
+			if n != 0 {
 				offset += int64(n)
-				if n != 0 {
-					// Copy the buffer since we will modify it in the next loop.
-					b := make([]byte, n)
-					copy(b, buf[:n])
-					ch <- b
-				}
-				// Clear the buffer.
-				for i := 0; i < n; i++ {
-					buf[i] = 0
+				// Copy the buffer since we will modify it in the next loop.
+				b := make([]byte, n)
+				copy(b, buf[:n])
+				select {
+				case ch <- b:
+				case <-ctx.Done():
+					return
 				}
+				continue
+			}
+
+			if !follow {
+				return
+			}
+
+			// Start over if the file was truncated, for example by log
+			// rotation.
+			if size, err := c.source.Seek(0, io.SeekEnd); err == nil && size < offset {
+				offset = 0
+				continue
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-c.options.Until:
+				// Read whatever was written before the channel was closed.
+				follow = false
+			case <-time.After(c.options.PollInterval):
 			}
 		}
 	}(ch)
 
 	return ch
 }
+
+// Err returns the error that ended the reading of the file, if any. It must
+// only be called once the channel returned by Read is closed.
+func (c *File) Err() error {
+	return c.err
+}
+
+// start returns the offset to start reading the file at.
+func (c *File) start() (offset int64, err error) {
+	size, err := c.source.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0, err
+	}
+
+	if c.options.TailLines > 0 {
+		if offset, err = TailOffset(c.source, size, c.options.TailLines); err != nil {
+			return 0, err
+		}
+	}
+
+	if !c.options.Since.IsZero() {
+		since, err := SinceOffset(c.source, size, c.options.Since)
+		if err != nil {
+			return 0, err
+		}
+		if since > offset {
+			offset = since
+		}
+	}
+
+	return offset, nil
+}
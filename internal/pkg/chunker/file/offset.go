@@ -0,0 +1,134 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package file
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"time"
+)
+
+// timestampLayouts are the layouts of the timestamps recognized at the start
+// of a line, along with the number of space separated fields they span.
+var timestampLayouts = []struct {
+	layout string
+	fields int
+}{
+	{time.RFC3339Nano, 1},
+	{"2006/01/02 15:04:05", 2},
+	{"2006-01-02 15:04:05", 2},
+}
+
+// timestampScanLines is the number of lines that HasTimestamps scans.
+const timestampScanLines = 100
+
+// TailOffset returns the offset of the start of the last n lines of the first
+// size bytes of r. A trailing newline does not start a new line.
+func TailOffset(r io.ReaderAt, size int64, n int) (int64, error) {
+	end := size
+	if end > 0 {
+		last := make([]byte, 1)
+		if _, err := r.ReadAt(last, end-1); err != nil && err != io.EOF {
+			return 0, err
+		}
+		if last[0] == '\n' {
+			end--
+		}
+	}
+
+	buf := make([]byte, 4096)
+	count := 0
+	for end > 0 {
+		start := end - int64(len(buf))
+		if start < 0 {
+			start = 0
+		}
+		chunk := buf[:end-start]
+		if _, err := r.ReadAt(chunk, start); err != nil && err != io.EOF {
+			return 0, err
+		}
+		for i := len(chunk) - 1; i >= 0; i-- {
+			if chunk[i] != '\n' {
+				continue
+			}
+			count++
+			if count == n {
+				return start + int64(i) + 1, nil
+			}
+		}
+		end = start
+	}
+
+	return 0, nil
+}
+
+// SinceOffset returns the offset of the first line of the first size bytes
+// of r that starts with a timestamp at or after t. Timestamps in RFC 3339
+// format, or in the format of the standard library's log package, are
+// recognized. If no such line exists, size is returned.
+func SinceOffset(r io.ReaderAt, size int64, t time.Time) (int64, error) {
+	reader := bufio.NewReader(io.NewSectionReader(r, 0, size))
+
+	var offset int64
+	for {
+		line, err := reader.ReadBytes('\n')
+		if ts, ok := ParseTimestamp(line); ok && !ts.Before(t) {
+			return offset, nil
+		}
+		offset += int64(len(line))
+		if err == io.EOF {
+			return size, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+}
+
+// HasTimestamps returns true if one of the first lines of the first size bytes
+// of r starts with a timestamp recognized by SinceOffset. Files without
+// timestamps, such as the logs written before the output of the services was
+// timestamped by a Timestamper, cannot be filtered by time. Only the first
+// lines are scanned, so that large logs are not read in full.
+func HasTimestamps(r io.ReaderAt, size int64) (bool, error) {
+	reader := bufio.NewReader(io.NewSectionReader(r, 0, size))
+
+	for i := 0; i < timestampScanLines; i++ {
+		line, err := reader.ReadBytes('\n')
+		if _, ok := ParseTimestamp(line); ok {
+			return true, nil
+		}
+		if err == io.EOF {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+	}
+
+	return false, nil
+}
+
+// ParseTimestamp parses the timestamp at the start of a line. See SinceOffset
+// for the timestamps that are recognized.
+func ParseTimestamp(line []byte) (time.Time, bool) {
+	// Timestamps are short, so there is no need to split the whole line.
+	if len(line) > 64 {
+		line = line[:64]
+	}
+	fields := bytes.Fields(line)
+	for _, l := range timestampLayouts {
+		if len(fields) < l.fields {
+			continue
+		}
+		value := string(bytes.Join(fields[:l.fields], []byte(" ")))
+		if ts, err := time.ParseInLocation(l.layout, value, time.Local); err == nil {
+			return ts, true
+		}
+	}
+
+	return time.Time{}, false
+}
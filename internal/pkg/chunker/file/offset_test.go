@@ -0,0 +1,96 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package file
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// nolint: scopelint
+func TestTailOffset(t *testing.T) {
+	type args struct {
+		data string
+		n    int
+	}
+	tests := []struct {
+		name string
+		args args
+		want int64
+	}{
+		{"empty", args{"", 3}, 0},
+		{"fewer lines", args{"a\nb\n", 3}, 0},
+		{"trailing newline", args{"a\nb\nc\n", 2}, 2},
+		{"no trailing newline", args{"a\nb\nc", 1}, 4},
+		{"all lines", args{"a\nb\nc\n", 3}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := strings.NewReader(tt.args.data)
+			got, err := TailOffset(r, int64(len(tt.args.data)), tt.args.n)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("TailOffset() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// nolint: scopelint
+func TestSinceOffset(t *testing.T) {
+	data := "no timestamp\n" +
+		"2019-03-01T10:00:00Z first\n" +
+		"continued\n" +
+		"2019-03-01T10:05:00.5Z second\n"
+	tests := []struct {
+		name  string
+		since time.Time
+		want  int64
+	}{
+		{"before all", time.Date(2019, 3, 1, 9, 0, 0, 0, time.UTC), 13},
+		{"between", time.Date(2019, 3, 1, 10, 1, 0, 0, time.UTC), 50},
+		{"after all", time.Date(2019, 3, 1, 11, 0, 0, 0, time.UTC), int64(len(data))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SinceOffset(strings.NewReader(data), int64(len(data)), tt.since)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("SinceOffset() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// nolint: scopelint
+func TestHasTimestamps(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want bool
+	}{
+		{"empty", "", false},
+		{"none", "starting\nready\n", false},
+		{"rfc3339", "starting\n2019-03-01T10:00:00Z ready\n", true},
+		{"log package", "2019/03/01 10:00:00 ready\n", true},
+		{"after the scanned lines", strings.Repeat("line\n", timestampScanLines) + "2019-03-01T10:00:00Z ready\n", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := HasTimestamps(strings.NewReader(tt.data), int64(len(tt.data)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("HasTimestamps() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package file
+
+import (
+	"bytes"
+	"io"
+	"sync"
+	"time"
+)
+
+// Timestamper prefixes the lines written to a log with the time at which they
+// are written, in RFC 3339 format, so that the output of processes that do not
+// timestamp their own lines can be filtered by time.
+type Timestamper struct {
+	mu  sync.Mutex
+	w   io.Writer
+	now func() time.Time
+}
+
+// NewTimestamper initializes a Timestamper that writes to w.
+func NewTimestamper(w io.Writer) *Timestamper {
+	return &Timestamper{w: w, now: time.Now}
+}
+
+// Writer returns an io.Writer for one stream of output, such as the stdout or
+// the stderr of a process. Each stream tracks the start of its own lines, so
+// that the streams can be written concurrently.
+func (t *Timestamper) Writer() io.Writer {
+	return &timestampWriter{t: t}
+}
+
+type timestampWriter struct {
+	t *Timestamper
+	// midLine is set when the last write did not end with a newline.
+	midLine bool
+}
+
+// Write implements the io.Writer interface.
+func (w *timestampWriter) Write(p []byte) (int, error) {
+	w.t.mu.Lock()
+	defer w.t.mu.Unlock()
+
+	var buf bytes.Buffer
+	for rest := p; len(rest) > 0; {
+		if !w.midLine {
+			buf.WriteString(w.t.now().UTC().Format(time.RFC3339Nano))
+			buf.WriteByte(' ')
+		}
+		i := bytes.IndexByte(rest, '\n')
+		if i < 0 {
+			buf.Write(rest)
+			w.midLine = true
+			break
+		}
+		buf.Write(rest[:i+1])
+		w.midLine = false
+		rest = rest[i+1:]
+	}
+
+	if _, err := w.t.w.Write(buf.Bytes()); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
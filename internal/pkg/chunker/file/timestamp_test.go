@@ -0,0 +1,46 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package file
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestTimestamper(t *testing.T) {
+	var buf bytes.Buffer
+	ts := NewTimestamper(&buf)
+	now := time.Date(2019, 3, 1, 10, 0, 0, 0, time.UTC)
+	ts.now = func() time.Time { return now }
+
+	stdout, stderr := ts.Writer(), ts.Writer()
+	writes := []struct {
+		w    io.Writer
+		data string
+	}{
+		{stdout, "first\nsec"},
+		{stdout, "ond\n"},
+		{stderr, "error\n\n"},
+	}
+	for _, write := range writes {
+		n, err := write.w.Write([]byte(write.data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != len(write.data) {
+			t.Errorf("Write() = %d, want %d", n, len(write.data))
+		}
+	}
+
+	want := "2019-03-01T10:00:00Z first\n2019-03-01T10:00:00Z second\n2019-03-01T10:00:00Z error\n2019-03-01T10:00:00Z \n"
+	if buf.String() != want {
+		t.Errorf("Timestamper wrote %q, want %q", buf.String(), want)
+	}
+	if got, ok := ParseTimestamp(buf.Bytes()); !ok || !got.Equal(now) {
+		t.Errorf("ParseTimestamp() = %v, %v, want %v, true", got, ok, now)
+	}
+}
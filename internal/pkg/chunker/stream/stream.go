@@ -31,15 +31,18 @@ func Size(s int) Option {
 type Stream struct {
 	source  Source
 	options *Options
+	err     error
 }
 
+var _ chunker.Chunker = (*Stream)(nil)
+
 // Source is an interface describing the source of a Stream.
 type Source interface {
 	io.ReadCloser
 }
 
 // NewChunker initializes a Chunker with default values.
-func NewChunker(source Source, setters ...Option) chunker.Chunker {
+func NewChunker(source Source, setters ...Option) *Stream {
 	opts := &Options{
 		Size: 1024,
 	}
@@ -49,8 +52,8 @@ func NewChunker(source Source, setters ...Option) chunker.Chunker {
 	}
 
 	return &Stream{
-		source,
-		opts,
+		source:  source,
+		options: opts,
 	}
 }
 
@@ -66,30 +69,31 @@ func (c *Stream) Read(ctx context.Context) <-chan []byte {
 
 		buf := make([]byte, c.options.Size)
 		for {
-			select {
-			case <-ctx.Done():
-				return
-			default:
-				n, err := c.source.Read(buf)
-				if err != nil {
-					if err != io.EOF {
-						fmt.Printf("read error: %s\n", err.Error())
-						break
-					}
+			n, err := c.source.Read(buf)
+			if n != 0 {
+				// Copy the buffer since we will modify it in the next loop.
+				b := make([]byte, n)
+				copy(b, buf[:n])
+				select {
+				case ch <- b:
+				case <-ctx.Done():
+					return
 				}
-				if n != 0 {
-					// Copy the buffer since we will modify it in the next loop.
-					b := make([]byte, n)
-					copy(b, buf[:n])
-					ch <- b
-				}
-				// Clear the buffer.
-				for i := 0; i < n; i++ {
-					buf[i] = 0
+			}
+			if err != nil {
+				if err != io.EOF {
+					c.err = fmt.Errorf("read error: %v", err)
 				}
+				return
 			}
 		}
 	}(ch)
 
 	return ch
 }
+
+// Err returns the error that ended the reading of the stream, if any. It must
+// only be called once the channel returned by Read is closed.
+func (c *Stream) Err() error {
+	return c.err
+}
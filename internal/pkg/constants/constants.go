@@ -13,6 +13,24 @@ const (
 	// platform.
 	KernelParamPlatform = "talos.autonomy.io/platform"
 
+	// KernelParamBootFailure is the kernel parameter name for specifying the
+	// action taken when the boot sequence fails. The supported values are
+	// BootFailureMaintenance, the default, and BootFailureReboot.
+	KernelParamBootFailure = "talos.autonomy.io/bootfailure"
+
+	// KernelParamMaintenanceInsecure is the kernel parameter name for
+	// allowing clients that are not authenticated to change the node in
+	// maintenance mode, when there is no node CA to authenticate them with.
+	// It is enabled with the value "true".
+	KernelParamMaintenanceInsecure = "talos.autonomy.io/maintenanceinsecure"
+
+	// BootFailureMaintenance is the boot failure action that serves the
+	// maintenance API.
+	BootFailureMaintenance = "maintenance"
+
+	// BootFailureReboot is the boot failure action that reboots the node.
+	BootFailureReboot = "reboot"
+
 	// NewRoot is the path where the switchroot target is mounted.
 	NewRoot = "/root"
 
@@ -94,9 +112,37 @@ const (
 	// OsdPort is the port for the osd service.
 	OsdPort = 50000
 
+	// AdminOrganization is the certificate organization of the clients with
+	// the admin role on the osd API.
+	AdminOrganization = "os:admin"
+
+	// OperatorOrganization is the certificate organization of the clients
+	// with the operator role on the osd API.
+	OperatorOrganization = "os:operator"
+
+	// ReadOnlyOrganization is the certificate organization of the clients
+	// with the read-only role on the osd API.
+	ReadOnlyOrganization = "os:readonly"
+
+	// NodeOrganization is the certificate organization of the node
+	// identities issued by trustd. The nodes with it may only forward the
+	// read-only role of a client.
+	NodeOrganization = "os:node"
+
+	// ControlPlaneOrganization is the certificate organization of the node
+	// identities signed locally with the OS CA key. The nodes with it may
+	// forward any role of a client.
+	ControlPlaneOrganization = "os:control-plane"
+
 	// TrustdPort is the port for the trustd service.
 	TrustdPort = 50001
 
+	// InitSocketPath is the path to the unix socket of the init API.
+	InitSocketPath = "/run/system/init/init.sock"
+
+	// BootTimelinePath is the path to the timeline of the boot sequence.
+	BootTimelinePath = "/run/system/boot/timeline.json"
+
 	// SystemContainerdNamespace is the Containerd namespace for Talos services.
 	SystemContainerdNamespace = "system"
 
@@ -249,7 +249,8 @@ func NewKey() (key *Key, err error) {
 }
 
 // NewCertificateFromCSR creates and signs X.509 certificate using the provided
-// CSR.
+// CSR. The organization of the CSR is never trusted: the certificate has the
+// organization set by the Organization option, or none.
 func NewCertificateFromCSR(ca *x509.Certificate, key *ecdsa.PrivateKey, csr *x509.CertificateRequest, setters ...Option) (crt *Certificate, err error) {
 	opts := NewDefaultOptions(setters...)
 	serialNumber, err := NewSerialNumber()
@@ -277,6 +278,12 @@ func NewCertificateFromCSR(ca *x509.Certificate, key *ecdsa.PrivateKey, csr *x50
 		IPAddresses: csr.IPAddresses,
 		DNSNames:    csr.DNSNames,
 	}
+	// The organization sets the role of the certificate, so only the signer
+	// may choose it.
+	template.Subject.Organization = nil
+	if opts.Organization != "" {
+		template.Subject.Organization = []string{opts.Organization}
+	}
 
 	crtDER, err := x509.CreateCertificate(rand.Reader, template, ca, csr.PublicKey, key)
 	if err != nil {
@@ -7,6 +7,8 @@ package factory
 import (
 	"crypto/tls"
 	"net"
+	"os"
+	"path/filepath"
 	"strconv"
 
 	"github.com/pkg/errors"
@@ -23,6 +25,7 @@ type Registrator interface {
 type Options struct {
 	Port          int
 	Network       string
+	SocketPath    string
 	Config        *tls.Config
 	ServerOptions []grpc.ServerOption
 }
@@ -44,6 +47,13 @@ func Network(o string) Option {
 	}
 }
 
+// SocketPath sets the listen path of the server when the network is unix.
+func SocketPath(o string) Option {
+	return func(args *Options) {
+		args.SocketPath = o
+	}
+}
+
 // Config sets the listen port of the server.
 func Config(o *tls.Config) Option {
 	return func(args *Options) {
@@ -61,7 +71,8 @@ func ServerOptions(o ...grpc.ServerOption) Option {
 // NewDefaultOptions initializes the Options struct with default values.
 func NewDefaultOptions(setters ...Option) *Options {
 	opts := &Options{
-		Network: "tcp",
+		Network:    "tcp",
+		SocketPath: "/run/factory/factory.sock",
 	}
 
 	for _, setter := range setters {
@@ -89,7 +100,14 @@ func Listen(r Registrator, setters ...Option) (err error) {
 	var address string
 	switch opts.Network {
 	case "unix":
-		address = "/run/factory/factory.sock"
+		address = opts.SocketPath
+		// Remove any stale socket, and ensure the parent directory exists.
+		if err = os.Remove(address); err != nil && !os.IsNotExist(err) {
+			return errors.Wrapf(err, "error removing stale socket %s", address)
+		}
+		if err = os.MkdirAll(filepath.Dir(address), 0700); err != nil {
+			return errors.Wrapf(err, "error creating socket directory for %s", address)
+		}
 	case "tcp":
 		address = ":" + strconv.Itoa(opts.Port)
 	default:
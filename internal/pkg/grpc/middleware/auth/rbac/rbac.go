@@ -0,0 +1,213 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package rbac
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/autonomy/talos/internal/pkg/constants"
+	"github.com/autonomy/talos/internal/pkg/net"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// RoleMetadataKey is the metadata key of the role of a client, forwarded by a
+// node that proxies its request.
+const RoleMetadataKey = "role"
+
+// Role represents the role of a client. A role is granted the permissions of
+// the roles below it.
+type Role int
+
+const (
+	// None is the role of the clients without a known organization.
+	None Role = iota
+	// ReadOnly is the role that can inspect a node.
+	ReadOnly
+	// Operator is the role that can also manage the services of a node.
+	Operator
+	// Admin is the role that can also reboot, reset and retrieve the secrets
+	// of a node.
+	Admin
+)
+
+// organizations maps the certificate organizations to the roles.
+var organizations = map[string]Role{
+	constants.ReadOnlyOrganization: ReadOnly,
+	constants.OperatorOrganization: Operator,
+	constants.AdminOrganization:    Admin,
+}
+
+// nodeOrganizations maps the certificate organizations of the nodes to the
+// highest role that they may forward. Only the nodes that sign their own
+// identity with the OS CA key are trusted with every role, since trustd
+// issues a node identity to any holder of its credentials.
+var nodeOrganizations = map[string]Role{
+	constants.NodeOrganization:         ReadOnly,
+	constants.ControlPlaneOrganization: Admin,
+}
+
+func (r Role) String() string {
+	switch r {
+	case ReadOnly:
+		return "read-only"
+	case Operator:
+		return "operator"
+	case Admin:
+		return "admin"
+	default:
+		return "none"
+	}
+}
+
+// ParseRole parses the role returned by Role.String.
+func ParseRole(s string) (Role, error) {
+	for r := ReadOnly; r <= Admin; r++ {
+		if r.String() == s {
+			return r, nil
+		}
+	}
+
+	return None, fmt.Errorf("unknown role %q", s)
+}
+
+type roleKey struct{}
+
+// NewContext returns a context that carries the role of the client.
+func NewContext(ctx context.Context, r Role) context.Context {
+	return context.WithValue(ctx, roleKey{}, r)
+}
+
+// FromContext returns the role of the client carried by the context, if any.
+func FromContext(ctx context.Context) (Role, bool) {
+	r, ok := ctx.Value(roleKey{}).(Role)
+
+	return r, ok
+}
+
+// Metadata returns the metadata that forwards the role of the client to the
+// node a request is proxied to.
+func Metadata(ctx context.Context) metadata.MD {
+	r, ok := FromContext(ctx)
+	if !ok {
+		return nil
+	}
+
+	return metadata.Pairs(RoleMetadataKey, r.String())
+}
+
+// Authorizer authorizes the requests with the role that the organization of
+// the client certificate maps to. The nodes are trusted with the role that
+// they forward for the client of a proxied request, up to the highest role of
+// their organization, when they connect from one of the IP addresses of their
+// certificate.
+type Authorizer struct {
+	// Rules are the roles required by the methods, keyed by their full name.
+	// The methods without a rule require the Admin role.
+	Rules map[string]Role
+}
+
+// UnaryInterceptor sets the UnaryServerInterceptor for the server and enforces
+// the rules.
+func (a *Authorizer) UnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, err := a.authorize(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+
+	return handler(ctx, req)
+}
+
+// StreamInterceptor sets the StreamServerInterceptor for the server and
+// enforces the rules.
+func (a *Authorizer) StreamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := a.authorize(ss.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+
+	return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+}
+
+func (a *Authorizer) authorize(ctx context.Context, method string) (context.Context, error) {
+	role, err := role(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	required, ok := a.Rules[method]
+	if !ok {
+		required = Admin
+	}
+	if role < required {
+		return nil, status.Errorf(codes.PermissionDenied, "%s requires the %s role, the client has the %s role", method, required, role)
+	}
+
+	return NewContext(ctx, role), nil
+}
+
+// role returns the role of the client, from the organizations of its
+// verified certificate.
+func role(ctx context.Context) (Role, error) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return None, status.Error(codes.Unauthenticated, "no peer")
+	}
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(info.State.VerifiedChains) == 0 || len(info.State.VerifiedChains[0]) == 0 {
+		return None, status.Error(codes.Unauthenticated, "no verified client certificate")
+	}
+
+	crt := info.State.VerifiedChains[0][0]
+	role := None
+	for _, organization := range crt.Subject.Organization {
+		if highest, ok := nodeOrganizations[organization]; ok {
+			// A node certificate is only trusted from the addresses of the
+			// node that it identifies.
+			if ip := net.AddrIP(p.Addr); !net.ContainsIP(crt.IPAddresses, ip) {
+				return None, status.Errorf(codes.PermissionDenied, "the node certificate is not valid for the peer address %s", ip)
+			}
+			if r := forwardedRole(ctx); r < highest {
+				return r, nil
+			}
+			return highest, nil
+		}
+		if r, ok := organizations[organization]; ok && r > role {
+			role = r
+		}
+	}
+
+	return role, nil
+}
+
+// forwardedRole returns the role forwarded by a node for the client of a
+// proxied request.
+func forwardedRole(ctx context.Context) Role {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok || len(md[RoleMetadataKey]) == 0 {
+		return None
+	}
+	r, err := ParseRole(md[RoleMetadataKey][0])
+	if err != nil {
+		return None
+	}
+
+	return r
+}
+
+// serverStream overrides the context of a grpc.ServerStream.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,151 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package rbac
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net"
+	"testing"
+
+	"github.com/autonomy/talos/internal/pkg/constants"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+func TestAuthorizer(t *testing.T) {
+	nodeAddr := &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 40000}
+	a := &Authorizer{
+		Rules: map[string]Role{
+			"/proto.OSD/Logs":           ReadOnly,
+			"/proto.OSD/ServiceRestart": Operator,
+			"/proto.OSD/Reset":          Admin,
+		},
+	}
+
+	type args struct {
+		organizations []string
+		forwarded     string
+		method        string
+		addr          net.Addr
+	}
+	tests := []struct {
+		name string
+		args args
+		want codes.Code
+	}{
+		{
+			name: "admin",
+			args: args{organizations: []string{constants.AdminOrganization}, method: "/proto.OSD/Reset"},
+			want: codes.OK,
+		},
+		{
+			name: "read-only reader method",
+			args: args{organizations: []string{constants.ReadOnlyOrganization}, method: "/proto.OSD/Logs"},
+			want: codes.OK,
+		},
+		{
+			name: "read-only admin method",
+			args: args{organizations: []string{constants.ReadOnlyOrganization}, method: "/proto.OSD/Reset"},
+			want: codes.PermissionDenied,
+		},
+		{
+			name: "operator",
+			args: args{organizations: []string{constants.ReadOnlyOrganization, constants.OperatorOrganization}, method: "/proto.OSD/ServiceRestart"},
+			want: codes.OK,
+		},
+		{
+			name: "no organization",
+			args: args{method: "/proto.OSD/Logs"},
+			want: codes.PermissionDenied,
+		},
+		{
+			name: "method without a rule",
+			args: args{organizations: []string{constants.OperatorOrganization}, method: "/proto.OSD/Unknown"},
+			want: codes.PermissionDenied,
+		},
+		{
+			name: "control plane node forwarding a role",
+			args: args{organizations: []string{constants.ControlPlaneOrganization}, forwarded: "admin", method: "/proto.OSD/Reset", addr: nodeAddr},
+			want: codes.OK,
+		},
+		{
+			name: "node forwarding the read-only role",
+			args: args{organizations: []string{constants.NodeOrganization}, forwarded: "read-only", method: "/proto.OSD/Logs", addr: nodeAddr},
+			want: codes.OK,
+		},
+		{
+			name: "node forwarding the admin role",
+			args: args{organizations: []string{constants.NodeOrganization}, forwarded: "admin", method: "/proto.OSD/Reset", addr: nodeAddr},
+			want: codes.PermissionDenied,
+		},
+		{
+			name: "node forwarding the operator role",
+			args: args{organizations: []string{constants.NodeOrganization}, forwarded: "operator", method: "/proto.OSD/ServiceRestart", addr: nodeAddr},
+			want: codes.PermissionDenied,
+		},
+		{
+			name: "node without a forwarded role",
+			args: args{organizations: []string{constants.NodeOrganization}, method: "/proto.OSD/Logs", addr: nodeAddr},
+			want: codes.PermissionDenied,
+		},
+		{
+			name: "node certificate forwarding a role from another address",
+			args: args{organizations: []string{constants.ControlPlaneOrganization}, forwarded: "admin", method: "/proto.OSD/Reset", addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.2"), Port: 40000}},
+			want: codes.PermissionDenied,
+		},
+		{
+			name: "node certificate forwarding a read-only role from another address",
+			args: args{organizations: []string{constants.NodeOrganization}, forwarded: "read-only", method: "/proto.OSD/Logs", addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.2"), Port: 40000}},
+			want: codes.PermissionDenied,
+		},
+		{
+			name: "node certificate forwarding a role without an address",
+			args: args{organizations: []string{constants.NodeOrganization}, forwarded: "admin", method: "/proto.OSD/Reset"},
+			want: codes.PermissionDenied,
+		},
+		{
+			name: "client forwarding a role",
+			args: args{organizations: []string{constants.ReadOnlyOrganization}, forwarded: "admin", method: "/proto.OSD/Reset"},
+			want: codes.PermissionDenied,
+		},
+	}
+	for _, tt := range tests {
+		// nolint: scopelint
+		t.Run(tt.name, func(t *testing.T) {
+			crt := &x509.Certificate{
+				Subject:     pkix.Name{Organization: tt.args.organizations},
+				IPAddresses: []net.IP{nodeAddr.IP},
+			}
+			ctx := peer.NewContext(context.Background(), &peer.Peer{
+				Addr: tt.args.addr,
+				AuthInfo: credentials.TLSInfo{
+					State: tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{crt}}},
+				},
+			})
+			if tt.args.forwarded != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(RoleMetadataKey, tt.args.forwarded))
+			}
+
+			_, err := a.authorize(ctx, tt.args.method)
+			if got := status.Code(err); got != tt.want {
+				t.Errorf("authorize() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuthorizer_Unauthenticated(t *testing.T) {
+	a := &Authorizer{}
+	ctx := peer.NewContext(context.Background(), &peer.Peer{AuthInfo: credentials.TLSInfo{}})
+	if _, err := a.authorize(ctx, "/proto.OSD/Logs"); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("authorize() = %v, want %v", status.Code(err), codes.Unauthenticated)
+	}
+}
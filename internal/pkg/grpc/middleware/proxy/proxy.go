@@ -0,0 +1,155 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// NodeMetadataKey is the metadata key of the node that a request is proxied
+// to.
+const NodeMetadataKey = "node"
+
+// Proxy forwards the requests that name a node in their metadata to the same
+// service on that node, so that a single reachable node is enough to reach
+// the others. The requests are forwarded with the client of the service. The
+// incoming metadata is not forwarded, so that a request is only proxied once.
+type Proxy struct {
+	// Port is the port of the service on the nodes.
+	Port int
+	// DialOptions are the options used to dial the nodes, for example the
+	// transport credentials of the proxying node.
+	DialOptions []grpc.DialOption
+	// NewClient initializes the client of the service, for example
+	// proto.NewOSDClient.
+	NewClient func(*grpc.ClientConn) interface{}
+	// Metadata returns the metadata forwarded with a request, if set.
+	Metadata func(context.Context) metadata.MD
+}
+
+// UnaryInterceptor sets the UnaryServerInterceptor for the server and proxies
+// the requests that name a node.
+func (p *Proxy) UnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	node, ok := node(ctx)
+	if !ok {
+		return handler(ctx, req)
+	}
+
+	conn, method, err := p.dial(ctx, node, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	// nolint: errcheck
+	defer conn.Close()
+
+	out := method.Call([]reflect.Value{reflect.ValueOf(p.outgoingContext(ctx)), reflect.ValueOf(req)})
+
+	return out[0].Interface(), callError(out[1])
+}
+
+// StreamInterceptor sets the StreamServerInterceptor for the server and
+// proxies the server streaming requests that name a node.
+func (p *Proxy) StreamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx := ss.Context()
+
+	node, ok := node(ctx)
+	if !ok {
+		return handler(srv, ss)
+	}
+	if info.IsClientStream {
+		return status.Errorf(codes.Unimplemented, "%s can't be proxied", info.FullMethod)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	conn, method, err := p.dial(ctx, node, info.FullMethod)
+	if err != nil {
+		return err
+	}
+	// nolint: errcheck
+	defer conn.Close()
+
+	req := reflect.New(method.Type().In(1).Elem())
+	if err = ss.RecvMsg(req.Interface()); err != nil {
+		return err
+	}
+
+	out := method.Call([]reflect.Value{reflect.ValueOf(p.outgoingContext(ctx)), req})
+	if err = callError(out[1]); err != nil {
+		return err
+	}
+
+	recv := out[0].MethodByName("Recv")
+	for {
+		out = recv.Call(nil)
+		err = callError(out[1])
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err = ss.SendMsg(out[0].Interface()); err != nil {
+			return err
+		}
+	}
+}
+
+// dial dials the node, and returns the method of the service client that
+// matches the full method name of the request.
+func (p *Proxy) dial(ctx context.Context, node, fullMethod string) (*grpc.ClientConn, reflect.Value, error) {
+	name := fullMethod[strings.LastIndex(fullMethod, "/")+1:]
+
+	conn, err := grpc.DialContext(ctx, fmt.Sprintf("%s:%d", node, p.Port), p.DialOptions...)
+	if err != nil {
+		return nil, reflect.Value{}, status.Errorf(codes.Unavailable, "%s: %v", node, err)
+	}
+
+	method := reflect.ValueOf(p.NewClient(conn)).MethodByName(name)
+	if !method.IsValid() {
+		// nolint: errcheck
+		conn.Close()
+		return nil, reflect.Value{}, status.Errorf(codes.Unimplemented, "%s can't be proxied", fullMethod)
+	}
+
+	return conn, method, nil
+}
+
+// outgoingContext returns the context of a forwarded request.
+func (p *Proxy) outgoingContext(ctx context.Context) context.Context {
+	if p.Metadata == nil {
+		return ctx
+	}
+
+	return metadata.NewOutgoingContext(ctx, p.Metadata(ctx))
+}
+
+// node returns the node named in the incoming metadata, if any.
+func node(ctx context.Context) (string, bool) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok || len(md[NodeMetadataKey]) == 0 || md[NodeMetadataKey][0] == "" {
+		return "", false
+	}
+
+	return md[NodeMetadataKey][0], true
+}
+
+func callError(v reflect.Value) error {
+	if v.IsNil() {
+		return nil
+	}
+
+	return v.Interface().(error)
+}
@@ -0,0 +1,89 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package proxy
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// nolint: gocyclo
+func TestProxy(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := &Proxy{
+		Port:        listener.Addr().(*net.TCPAddr).Port,
+		DialOptions: []grpc.DialOption{grpc.WithInsecure()},
+		NewClient: func(conn *grpc.ClientConn) interface{} {
+			return healthpb.NewHealthClient(conn)
+		},
+	}
+
+	var proxied int32
+	server := grpc.NewServer(
+		grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			if _, ok := node(ctx); ok {
+				atomic.AddInt32(&proxied, 1)
+			}
+			return p.UnaryInterceptor(ctx, req, info, handler)
+		}),
+		grpc.StreamInterceptor(p.StreamInterceptor),
+	)
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus("osd", healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(server, healthServer)
+	// nolint: errcheck
+	go server.Serve(listener)
+	defer server.Stop()
+
+	conn, err := grpc.Dial(listener.Addr().String(), grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// nolint: errcheck
+	defer conn.Close()
+	client := healthpb.NewHealthClient(conn)
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), NodeMetadataKey, "127.0.0.1")
+
+	reply, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: "osd"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reply.Status != healthpb.HealthCheckResponse_SERVING {
+		t.Errorf("Check() = %v, want %v", reply.Status, healthpb.HealthCheckResponse_SERVING)
+	}
+	if n := atomic.LoadInt32(&proxied); n != 1 {
+		t.Errorf("the request was proxied %d times, want 1", n)
+	}
+
+	_, err = client.Check(ctx, &healthpb.HealthCheckRequest{Service: "unknown"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("Check() error = %v, want the error of the node", err)
+	}
+
+	stream, err := client.Watch(ctx, &healthpb.HealthCheckRequest{Service: "osd"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reply, err = stream.Recv(); err != nil {
+		t.Fatal(err)
+	}
+	if reply.Status != healthpb.HealthCheckResponse_SERVING {
+		t.Errorf("Watch() = %v, want %v", reply.Status, healthpb.HealthCheckResponse_SERVING)
+	}
+}
@@ -0,0 +1,212 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package kmsg
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// Path is the path to the kernel log device.
+const Path = "/dev/kmsg"
+
+// pollTimeout is the time between checks of the context while waiting for new
+// records.
+const pollTimeout = 250 * time.Millisecond
+
+// Facilities are the names of the syslog facilities, indexed by their code.
+var Facilities = []string{
+	"kern", "user", "mail", "daemon", "auth", "syslog", "lpr", "news",
+	"uucp", "cron", "authpriv", "ftp", "ntp", "security", "console", "solaris-cron",
+	"local0", "local1", "local2", "local3", "local4", "local5", "local6", "local7",
+}
+
+// Priorities are the names of the syslog priorities, indexed by their code.
+var Priorities = []string{"emerg", "alert", "crit", "err", "warn", "notice", "info", "debug"}
+
+// Record is a record of the kernel log.
+type Record struct {
+	Facility int
+	Priority int
+	Sequence uint64
+	// Monotonic is the time of the record since boot.
+	Monotonic time.Duration
+	// Timestamp is the wall clock time of the record.
+	Timestamp time.Time
+	Message   string
+}
+
+// FacilityName returns the name of a facility code.
+func FacilityName(facility int) string {
+	if facility >= 0 && facility < len(Facilities) {
+		return Facilities[facility]
+	}
+
+	return strconv.Itoa(facility)
+}
+
+// PriorityName returns the name of a priority code.
+func PriorityName(priority int) string {
+	if priority >= 0 && priority < len(Priorities) {
+		return Priorities[priority]
+	}
+
+	return strconv.Itoa(priority)
+}
+
+// ParsePriority returns the code of a priority name.
+func ParsePriority(name string) (int, error) {
+	for i, p := range Priorities {
+		if p == name {
+			return i, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown priority %q, expected one of %s", name, strings.Join(Priorities, ", "))
+}
+
+// BootTime returns the wall clock time at which the monotonic clock, and so
+// the timestamps of the kernel log, started.
+func BootTime() (time.Time, error) {
+	var ts unix.Timespec
+	if err := unix.ClockGettime(unix.CLOCK_MONOTONIC, &ts); err != nil {
+		return time.Time{}, err
+	}
+
+	return time.Now().Add(-time.Duration(ts.Nano())), nil
+}
+
+// Parse parses a record read from /dev/kmsg, of the form
+// "priority,sequence,timestamp,flags;message". The timestamp is in
+// microseconds since boot, and is converted to wall clock time relative to
+// boot.
+func Parse(s string, boot time.Time) (*Record, error) {
+	i := strings.IndexByte(s, ';')
+	if i < 0 {
+		return nil, fmt.Errorf("malformed kmsg record: %q", s)
+	}
+	fields := strings.Split(s[:i], ",")
+	if len(fields) < 3 {
+		return nil, fmt.Errorf("malformed kmsg record header: %q", s[:i])
+	}
+	priority, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return nil, fmt.Errorf("malformed kmsg record priority: %v", err)
+	}
+	sequence, err := strconv.ParseUint(fields[1], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("malformed kmsg record sequence: %v", err)
+	}
+	usec, err := strconv.ParseInt(fields[2], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("malformed kmsg record timestamp: %v", err)
+	}
+
+	// Continuation lines, describing the device, follow the message.
+	message := s[i+1:]
+	if j := strings.IndexByte(message, '\n'); j >= 0 {
+		message = message[:j]
+	}
+
+	monotonic := time.Duration(usec) * time.Microsecond
+
+	return &Record{
+		Facility:  priority >> 3,
+		Priority:  priority & 7,
+		Sequence:  sequence,
+		Monotonic: monotonic,
+		Timestamp: boot.Add(monotonic),
+		Message:   message,
+	}, nil
+}
+
+// Read streams the records of the kernel log, starting with the oldest record
+// still in the buffer. If follow is false, the channel is closed once the
+// records in the buffer have been read. Otherwise, new records are streamed
+// until the context is canceled.
+// nolint: gocyclo
+func Read(ctx context.Context, follow bool) (<-chan *Record, error) {
+	boot, err := BootTime()
+	if err != nil {
+		return nil, err
+	}
+
+	fd, err := unix.Open(Path, unix.O_RDONLY|unix.O_NONBLOCK|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %v", Path, err)
+	}
+
+	ch := make(chan *Record)
+
+	go func() {
+		defer close(ch)
+		// nolint: errcheck
+		defer unix.Close(fd)
+
+		// Every read returns a single record.
+		buf := make([]byte, 8192)
+		for {
+			n, err := unix.Read(fd, buf)
+			switch {
+			case err == unix.EPIPE:
+				// Records were overwritten before they were read.
+				continue
+			case err == unix.EAGAIN:
+				if !follow {
+					return
+				}
+				if !wait(ctx, fd) {
+					return
+				}
+				continue
+			case err == unix.EINTR:
+				continue
+			case err != nil:
+				log.Printf("failed to read %s: %v", Path, err)
+				return
+			}
+
+			record, err := Parse(string(buf[:n]), boot)
+			if err != nil {
+				continue
+			}
+
+			select {
+			case ch <- record:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return ch, nil
+}
+
+// wait waits for the file descriptor to become readable. It returns false if
+// the context is canceled.
+func wait(ctx context.Context, fd int) bool {
+	fds := []unix.PollFd{{Fd: int32(fd), Events: unix.POLLIN}}
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		default:
+		}
+
+		n, err := unix.Poll(fds, int(pollTimeout/time.Millisecond))
+		if err != nil && err != unix.EINTR {
+			return false
+		}
+		if n > 0 {
+			return true
+		}
+	}
+}
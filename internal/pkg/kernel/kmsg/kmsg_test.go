@@ -0,0 +1,62 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package kmsg
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// nolint: scopelint
+func TestParse(t *testing.T) {
+	boot := time.Date(2019, 3, 1, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		s       string
+		want    *Record
+		wantErr bool
+	}{
+		{
+			"kernel",
+			"6,339,5140900,-;NET: Registered protocol family 10\n SUBSYSTEM=net\n",
+			&Record{
+				Facility:  0,
+				Priority:  6,
+				Sequence:  339,
+				Monotonic: 5140900 * time.Microsecond,
+				Timestamp: boot.Add(5140900 * time.Microsecond),
+				Message:   "NET: Registered protocol family 10",
+			},
+			false,
+		},
+		{
+			"user space",
+			"12,340,6000000,-;[talos] starting\n",
+			&Record{
+				Facility:  1,
+				Priority:  4,
+				Sequence:  340,
+				Monotonic: 6 * time.Second,
+				Timestamp: boot.Add(6 * time.Second),
+				Message:   "[talos] starting",
+			},
+			false,
+		},
+		{"no header", "no header", nil, true},
+		{"bad priority", "x,1,2,-;msg", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.s, boot)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package mount
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Entry represents a line of the mount table.
+type Entry struct {
+	Source  string
+	Target  string
+	Fstype  string
+	Options string
+}
+
+// Table returns the mount table of the calling process.
+func Table() ([]*Entry, error) {
+	f, err := os.Open("/proc/self/mounts")
+	if err != nil {
+		return nil, err
+	}
+	// nolint: errcheck
+	defer f.Close()
+
+	return ParseTable(f)
+}
+
+// ParseTable parses a mount table in the format of /proc/mounts.
+func ParseTable(r io.Reader) ([]*Entry, error) {
+	entries := []*Entry{}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 4 {
+			continue
+		}
+		entries = append(entries, &Entry{
+			Source:  unescape(fields[0]),
+			Target:  unescape(fields[1]),
+			Fstype:  unescape(fields[2]),
+			Options: unescape(fields[3]),
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// unescape decodes the octal escapes used by the kernel for the spaces, tabs,
+// newlines and backslashes in the fields of the mount table.
+func unescape(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+4 <= len(s) {
+			if c, err := strconv.ParseUint(s[i+1:i+4], 8, 8); err == nil {
+				b.WriteByte(byte(c))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+
+	return b.String()
+}
@@ -0,0 +1,50 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package mount
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseTable(t *testing.T) {
+	type args struct {
+		table string
+	}
+	tests := []struct {
+		name string
+		args args
+		want []*Entry
+	}{
+		{
+			name: "mounts",
+			args: args{table: "/dev/sda3 /var xfs rw,noatime 0 0\nproc /proc proc rw,nosuid 0 0\n"},
+			want: []*Entry{
+				{Source: "/dev/sda3", Target: "/var", Fstype: "xfs", Options: "rw,noatime"},
+				{Source: "proc", Target: "/proc", Fstype: "proc", Options: "rw,nosuid"},
+			},
+		},
+		{
+			name: "escaped",
+			args: args{table: `tmpfs /mnt/a\040b\134c tmpfs rw 0 0`},
+			want: []*Entry{
+				{Source: "tmpfs", Target: `/mnt/a b\c`, Fstype: "tmpfs", Options: "rw"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		// nolint: scopelint
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTable(strings.NewReader(tt.args.table))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseTable() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
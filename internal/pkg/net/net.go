@@ -29,3 +29,36 @@ func IPAddrs() (ips []net.IP, err error) {
 
 	return ips, nil
 }
+
+// AddrIP returns the IP address of a network address, or nil if the address
+// has none.
+func AddrIP(addr net.Addr) net.IP {
+	switch a := addr.(type) {
+	case *net.TCPAddr:
+		return a.IP
+	case *net.UDPAddr:
+		return a.IP
+	case nil:
+		return nil
+	default:
+		host, _, err := net.SplitHostPort(a.String())
+		if err != nil {
+			return nil
+		}
+		return net.ParseIP(host)
+	}
+}
+
+// ContainsIP reports whether the IP address is one of the IP addresses.
+func ContainsIP(ips []net.IP, ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+	for _, i := range ips {
+		if i.Equal(ip) {
+			return true
+		}
+	}
+
+	return false
+}
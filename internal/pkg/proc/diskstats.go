@@ -0,0 +1,84 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package proc
+
+import (
+	"bufio"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// SectorSize is the size of the sectors counted in /proc/diskstats,
+// regardless of the sector size of the device.
+const SectorSize = 512
+
+// DiskStat represents the I/O statistics of a block device in
+// /proc/diskstats. The times are in milliseconds.
+type DiskStat struct {
+	Major           uint32
+	Minor           uint32
+	Name            string
+	ReadsCompleted  uint64
+	ReadsMerged     uint64
+	SectorsRead     uint64
+	ReadTime        uint64
+	WritesCompleted uint64
+	WritesMerged    uint64
+	SectorsWritten  uint64
+	WriteTime       uint64
+	IOInProgress    uint64
+	IOTime          uint64
+	WeightedIOTime  uint64
+}
+
+// ReadDiskStats reads /proc/diskstats.
+func ReadDiskStats() (d []*DiskStat, err error) {
+	err = parse(filepath.Join(Path, "diskstats"), func(r io.Reader) error {
+		d, err = ParseDiskStats(r)
+		return err
+	})
+
+	return d, err
+}
+
+// ParseDiskStats parses the I/O statistics in the format of /proc/diskstats.
+func ParseDiskStats(r io.Reader) ([]*DiskStat, error) {
+	stats := []*DiskStat{}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 14 {
+			continue
+		}
+		stat := &DiskStat{
+			Major: uint32(parseUint(fields[0])),
+			Minor: uint32(parseUint(fields[1])),
+			Name:  fields[2],
+		}
+		for i, field := range []*uint64{
+			&stat.ReadsCompleted,
+			&stat.ReadsMerged,
+			&stat.SectorsRead,
+			&stat.ReadTime,
+			&stat.WritesCompleted,
+			&stat.WritesMerged,
+			&stat.SectorsWritten,
+			&stat.WriteTime,
+			&stat.IOInProgress,
+			&stat.IOTime,
+			&stat.WeightedIOTime,
+		} {
+			*field = parseUint(fields[i+3])
+		}
+		stats = append(stats, stat)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
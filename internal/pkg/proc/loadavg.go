@@ -0,0 +1,64 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package proc
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// LoadAvg represents the load averages of /proc/loadavg.
+type LoadAvg struct {
+	Load1  float64
+	Load5  float64
+	Load15 float64
+	// Running is the number of runnable threads.
+	Running uint64
+	// Total is the number of threads.
+	Total uint64
+}
+
+// ReadLoadAvg reads /proc/loadavg.
+func ReadLoadAvg() (l *LoadAvg, err error) {
+	err = parse(filepath.Join(Path, "loadavg"), func(r io.Reader) error {
+		l, err = ParseLoadAvg(r)
+		return err
+	})
+
+	return l, err
+}
+
+// ParseLoadAvg parses the load averages in the format of /proc/loadavg.
+func ParseLoadAvg(r io.Reader) (*LoadAvg, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := strings.Fields(string(b))
+	if len(fields) < 4 {
+		return nil, fmt.Errorf("malformed load averages: %q", b)
+	}
+
+	l := &LoadAvg{}
+	for i, field := range []*float64{&l.Load1, &l.Load5, &l.Load15} {
+		if *field, err = strconv.ParseFloat(fields[i], 64); err != nil {
+			return nil, err
+		}
+	}
+
+	threads := strings.SplitN(fields[3], "/", 2)
+	if len(threads) != 2 {
+		return nil, fmt.Errorf("malformed load averages: %q", b)
+	}
+	l.Running = parseUint(threads[0])
+	l.Total = parseUint(threads[1])
+
+	return l, nil
+}
@@ -0,0 +1,75 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package proc
+
+import (
+	"bufio"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// Meminfo represents the memory statistics of /proc/meminfo. The sizes are
+// in bytes.
+type Meminfo struct {
+	MemTotal     uint64
+	MemFree      uint64
+	MemAvailable uint64
+	Buffers      uint64
+	Cached       uint64
+	Shmem        uint64
+	Slab         uint64
+	Dirty        uint64
+	SwapTotal    uint64
+	SwapFree     uint64
+}
+
+// ReadMeminfo reads /proc/meminfo.
+func ReadMeminfo() (m *Meminfo, err error) {
+	err = parse(filepath.Join(Path, "meminfo"), func(r io.Reader) error {
+		m, err = ParseMeminfo(r)
+		return err
+	})
+
+	return m, err
+}
+
+// ParseMeminfo parses the memory statistics in the format of /proc/meminfo.
+func ParseMeminfo(r io.Reader) (*Meminfo, error) {
+	m := &Meminfo{}
+	fields := map[string]*uint64{
+		"MemTotal":     &m.MemTotal,
+		"MemFree":      &m.MemFree,
+		"MemAvailable": &m.MemAvailable,
+		"Buffers":      &m.Buffers,
+		"Cached":       &m.Cached,
+		"Shmem":        &m.Shmem,
+		"Slab":         &m.Slab,
+		"Dirty":        &m.Dirty,
+		"SwapTotal":    &m.SwapTotal,
+		"SwapFree":     &m.SwapFree,
+	}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.Fields(scanner.Text())
+		if len(line) < 2 {
+			continue
+		}
+		field, ok := fields[strings.TrimSuffix(line[0], ":")]
+		if !ok {
+			continue
+		}
+		*field = parseUint(line[1])
+		if len(line) > 2 && line[2] == "kB" {
+			*field *= 1024
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
@@ -0,0 +1,116 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package proc
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// PressureResources are the resources that the pressure stall information is
+// reported for.
+var PressureResources = []string{"cpu", "memory", "io"}
+
+// PressureLine represents the share of time that tasks were stalled on a
+// resource, as percentages averaged over 10, 60 and 300 seconds, and the
+// total stall time in microseconds.
+type PressureLine struct {
+	Avg10  float64
+	Avg60  float64
+	Avg300 float64
+	Total  uint64
+}
+
+// Pressure represents the pressure stall information of a resource. Some is
+// the time that at least some tasks were stalled, and Full is the time that
+// all of the tasks were stalled.
+type Pressure struct {
+	Resource string
+	Some     *PressureLine
+	Full     *PressureLine
+}
+
+// ReadPressure reads /proc/pressure. The resources are skipped when the
+// kernel does not support the pressure stall information.
+func ReadPressure() ([]*Pressure, error) {
+	pressure := []*Pressure{}
+	for _, resource := range PressureResources {
+		var p *Pressure
+		err := parse(filepath.Join(Path, "pressure", resource), func(r io.Reader) (err error) {
+			p, err = ParsePressure(resource, r)
+			return err
+		})
+		if err != nil {
+			if os.IsNotExist(err) || isNotSupported(err) {
+				continue
+			}
+			return nil, err
+		}
+		pressure = append(pressure, p)
+	}
+
+	return pressure, nil
+}
+
+// ParsePressure parses the pressure stall information of a resource in the
+// format of /proc/pressure.
+func ParsePressure(resource string, r io.Reader) (*Pressure, error) {
+	p := &Pressure{Resource: resource}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		line := &PressureLine{}
+		for _, field := range fields[1:] {
+			kv := strings.SplitN(field, "=", 2)
+			if len(kv) != 2 {
+				continue
+			}
+			var err error
+			switch kv[0] {
+			case "avg10":
+				line.Avg10, err = strconv.ParseFloat(kv[1], 64)
+			case "avg60":
+				line.Avg60, err = strconv.ParseFloat(kv[1], 64)
+			case "avg300":
+				line.Avg300, err = strconv.ParseFloat(kv[1], 64)
+			case "total":
+				line.Total, err = strconv.ParseUint(kv[1], 10, 64)
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+		switch fields[0] {
+		case "some":
+			p.Some = line
+		case "full":
+			p.Full = line
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// isNotSupported reports whether the pressure stall information is disabled
+// on the kernel command line, in which case reading it fails.
+func isNotSupported(err error) bool {
+	if e, ok := err.(*os.PathError); ok {
+		err = e.Err
+	}
+
+	return err == syscall.EOPNOTSUPP
+}
@@ -0,0 +1,33 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+// Package proc parses the system statistics exposed in /proc.
+package proc
+
+import (
+	"io"
+	"os"
+	"strconv"
+)
+
+// Path is the mount point of the proc filesystem.
+var Path = "/proc"
+
+func parse(name string, fn func(io.Reader) error) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	// nolint: errcheck
+	defer f.Close()
+
+	return fn(f)
+}
+
+func parseUint(s string) uint64 {
+	// nolint: errcheck
+	n, _ := strconv.ParseUint(s, 10, 64)
+
+	return n
+}
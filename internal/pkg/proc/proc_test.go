@@ -0,0 +1,132 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package proc
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseStat(t *testing.T) {
+	type args struct {
+		stat string
+	}
+	tests := []struct {
+		name string
+		args args
+		want *Stat
+	}{
+		{
+			name: "stat",
+			args: args{stat: "cpu  10 1 20 300 4 0 5 0 0 0\ncpu0 10 1 20 300 4 0 5 0 0 0\nintr 1 2 3\nctxt 42\nbtime 1546300800\nprocesses 7\nprocs_running 2\nprocs_blocked 1\n"},
+			want: &Stat{
+				CPU:             &CPUStat{Name: "cpu", User: 10, Nice: 1, System: 20, Idle: 300, Iowait: 4, SoftIRQ: 5},
+				CPUs:            []*CPUStat{{Name: "cpu0", User: 10, Nice: 1, System: 20, Idle: 300, Iowait: 4, SoftIRQ: 5}},
+				ContextSwitches: 42,
+				BootTime:        1546300800,
+				Forks:           7,
+				ProcsRunning:    2,
+				ProcsBlocked:    1,
+			},
+		},
+		{
+			name: "fewer modes",
+			args: args{stat: "cpu 1 2 3 4\n"},
+			want: &Stat{
+				CPU: &CPUStat{Name: "cpu", User: 1, Nice: 2, System: 3, Idle: 4},
+			},
+		},
+	}
+	for _, tt := range tests {
+		// nolint: scopelint
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseStat(strings.NewReader(tt.args.stat))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseStat() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePressure(t *testing.T) {
+	type args struct {
+		pressure string
+	}
+	tests := []struct {
+		name string
+		args args
+		want *Pressure
+	}{
+		{
+			name: "cpu",
+			args: args{pressure: "some avg10=1.50 avg60=0.25 avg300=0.00 total=12345\n"},
+			want: &Pressure{
+				Resource: "cpu",
+				Some:     &PressureLine{Avg10: 1.5, Avg60: 0.25, Total: 12345},
+			},
+		},
+		{
+			name: "memory",
+			args: args{pressure: "some avg10=0.00 avg60=0.00 avg300=0.00 total=10\nfull avg10=0.00 avg60=0.00 avg300=0.00 total=5\n"},
+			want: &Pressure{
+				Resource: "memory",
+				Some:     &PressureLine{Total: 10},
+				Full:     &PressureLine{Total: 5},
+			},
+		},
+	}
+	for _, tt := range tests {
+		// nolint: scopelint
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParsePressure(tt.name, strings.NewReader(tt.args.pressure))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParsePressure() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseProcessStat(t *testing.T) {
+	type args struct {
+		stat string
+	}
+	tests := []struct {
+		name string
+		args args
+		want *Process
+	}{
+		{
+			name: "process",
+			args: args{stat: "42 (containerd) S 1 42 42 0 -1 4194560 100 0 0 0 150 50 0 0 20 0 12 0 300 1048576 10 18446744073709551615"},
+			want: &Process{Pid: 42, PPid: 1, State: "S", Command: "containerd", ResidentMemory: 10 * uint64(os.Getpagesize()), VirtualMemory: 1048576, CPUTime: 2 * time.Second, Threads: 12},
+		},
+		{
+			name: "command with parentheses",
+			args: args{stat: "7 (a) (b)) R 2 0 0 0 -1 0 0 0 0 0 1 0 0 0 20 0 1 0 300 0 0 0"},
+			want: &Process{Pid: 7, PPid: 2, State: "R", Command: "a) (b)", CPUTime: 10 * time.Millisecond, Threads: 1},
+		},
+	}
+	for _, tt := range tests {
+		// nolint: scopelint
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseProcessStat([]byte(tt.args.stat))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseProcessStat() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
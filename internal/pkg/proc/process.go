@@ -0,0 +1,178 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package proc
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ClockTicks is the number of USER_HZ per second, in which the CPU times are
+// reported. It is fixed by the kernel ABI.
+const ClockTicks = 100
+
+// Process represents a process of /proc.
+type Process struct {
+	Pid   int32
+	PPid  int32
+	State string
+	// Command is the name of the executable, as truncated by the kernel.
+	Command    string
+	Args       []string
+	Executable string
+	// ResidentMemory and VirtualMemory are in bytes.
+	ResidentMemory uint64
+	VirtualMemory  uint64
+	// CPUTime is the time spent in user and kernel mode.
+	CPUTime time.Duration
+	Threads int32
+	Cgroup  string
+}
+
+// ReadProcesses reads the processes of /proc. The processes that exit while
+// they are read are skipped.
+func ReadProcesses() ([]*Process, error) {
+	infos, err := ioutil.ReadDir(Path)
+	if err != nil {
+		return nil, err
+	}
+
+	processes := []*Process{}
+	for _, info := range infos {
+		pid, err := strconv.ParseInt(info.Name(), 10, 32)
+		if err != nil || !info.IsDir() {
+			continue
+		}
+		p, err := ReadProcess(int32(pid))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		processes = append(processes, p)
+	}
+
+	return processes, nil
+}
+
+// ReadProcess reads the process with the given pid. The executable and the
+// command line are left empty for the kernel threads.
+func ReadProcess(pid int32) (*Process, error) {
+	dir := filepath.Join(Path, strconv.Itoa(int(pid)))
+
+	stat, err := ioutil.ReadFile(filepath.Join(dir, "stat"))
+	if err != nil {
+		return nil, err
+	}
+	p, err := ParseProcessStat(stat)
+	if err != nil {
+		return nil, err
+	}
+
+	cmdline, err := ioutil.ReadFile(filepath.Join(dir, "cmdline"))
+	if err != nil {
+		return nil, err
+	}
+	if cmdline = bytes.TrimRight(cmdline, "\x00"); len(cmdline) > 0 {
+		p.Args = strings.Split(string(cmdline), "\x00")
+	}
+
+	// The link is only readable with the privileges of the owner.
+	// nolint: errcheck
+	p.Executable, _ = os.Readlink(filepath.Join(dir, "exe"))
+
+	err = parse(filepath.Join(dir, "cgroup"), func(r io.Reader) (err error) {
+		p.Cgroup, err = ParseCgroup(r)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// ParseProcessStat parses the status of a process in the format of
+// /proc/[pid]/stat.
+func ParseProcessStat(stat []byte) (*Process, error) {
+	// The command is in parentheses, and may itself contain spaces and
+	// parentheses.
+	start := bytes.IndexByte(stat, '(')
+	end := bytes.LastIndexByte(stat, ')')
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("malformed process status: %q", stat)
+	}
+
+	pid, err := strconv.ParseInt(string(bytes.TrimSpace(stat[:start])), 10, 32)
+	if err != nil {
+		return nil, err
+	}
+
+	// The fields are numbered from the state, the third field in proc(5).
+	fields := strings.Fields(string(stat[end+1:]))
+	if len(fields) < 22 {
+		return nil, fmt.Errorf("malformed process status: %q", stat)
+	}
+
+	ppid, err := strconv.ParseInt(fields[1], 10, 32)
+	if err != nil {
+		return nil, err
+	}
+	threads, err := strconv.ParseInt(fields[17], 10, 32)
+	if err != nil {
+		return nil, err
+	}
+
+	ticks := parseUint(fields[11]) + parseUint(fields[12])
+
+	return &Process{
+		Pid:            int32(pid),
+		PPid:           int32(ppid),
+		State:          fields[0],
+		Command:        string(stat[start+1 : end]),
+		ResidentMemory: parseUint(fields[21]) * uint64(os.Getpagesize()),
+		VirtualMemory:  parseUint(fields[20]),
+		CPUTime:        time.Duration(ticks) * time.Second / ClockTicks,
+		Threads:        int32(threads),
+	}, nil
+}
+
+// ParseCgroup parses the control groups of a process in the format of
+// /proc/[pid]/cgroup, and returns the path of the memory controller, or the
+// path of the unified hierarchy.
+func ParseCgroup(r io.Reader) (string, error) {
+	var unified string
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.SplitN(scanner.Text(), ":", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		if fields[0] == "0" && fields[1] == "" {
+			unified = fields[2]
+			continue
+		}
+		for _, controller := range strings.Split(fields[1], ",") {
+			if controller == "memory" {
+				return fields[2], nil
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+
+	return unified, nil
+}
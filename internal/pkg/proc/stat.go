@@ -0,0 +1,115 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package proc
+
+import (
+	"bufio"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// CPUStat represents the time spent by a CPU in each mode, in USER_HZ. The
+// guest time is also accounted in the user time.
+type CPUStat struct {
+	Name      string
+	User      uint64
+	Nice      uint64
+	System    uint64
+	Idle      uint64
+	Iowait    uint64
+	IRQ       uint64
+	SoftIRQ   uint64
+	Steal     uint64
+	Guest     uint64
+	GuestNice uint64
+}
+
+// Stat represents the kernel statistics of /proc/stat.
+type Stat struct {
+	// CPU is the time spent by all of the CPUs.
+	CPU *CPUStat
+	// CPUs is the time spent by each CPU.
+	CPUs            []*CPUStat
+	ContextSwitches uint64
+	BootTime        uint64
+	Forks           uint64
+	ProcsRunning    uint64
+	ProcsBlocked    uint64
+}
+
+// ReadStat reads /proc/stat.
+func ReadStat() (s *Stat, err error) {
+	err = parse(filepath.Join(Path, "stat"), func(r io.Reader) error {
+		s, err = ParseStat(r)
+		return err
+	})
+
+	return s, err
+}
+
+// ParseStat parses the kernel statistics in the format of /proc/stat.
+func ParseStat(r io.Reader) (*Stat, error) {
+	s := &Stat{}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		switch fields[0] {
+		case "ctxt":
+			s.ContextSwitches = parseUint(fields[1])
+		case "btime":
+			s.BootTime = parseUint(fields[1])
+		case "processes":
+			s.Forks = parseUint(fields[1])
+		case "procs_running":
+			s.ProcsRunning = parseUint(fields[1])
+		case "procs_blocked":
+			s.ProcsBlocked = parseUint(fields[1])
+		default:
+			if !strings.HasPrefix(fields[0], "cpu") {
+				continue
+			}
+			cpu := parseCPUStat(fields)
+			if cpu.Name == "cpu" {
+				s.CPU = cpu
+			} else {
+				s.CPUs = append(s.CPUs, cpu)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func parseCPUStat(fields []string) *CPUStat {
+	cpu := &CPUStat{Name: fields[0]}
+	// Older kernels report fewer modes.
+	for i, field := range []*uint64{
+		&cpu.User,
+		&cpu.Nice,
+		&cpu.System,
+		&cpu.Idle,
+		&cpu.Iowait,
+		&cpu.IRQ,
+		&cpu.SoftIRQ,
+		&cpu.Steal,
+		&cpu.Guest,
+		&cpu.GuestNice,
+	} {
+		if i+1 >= len(fields) {
+			break
+		}
+		*field = parseUint(fields[i+1])
+	}
+
+	return cpu
+}
@@ -80,6 +80,33 @@ type Services struct {
 	Blockd  *Blockd  `yaml:"blockd"`
 	OSD     *OSD     `yaml:"osd"`
 	CRT     *CRT     `yaml:"crt"`
+
+	Extensions []*Extension `yaml:"extensions,omitempty"`
+}
+
+// Extension describes a user defined system container. It is run by init in
+// the system namespace, next to the built-in system services. The restart
+// policy is one of "Always" (the default), "OnFailure", or "Never". The
+// memory limit is in bytes, and zero means that there is no limit. If args
+// is empty, the entrypoint of the image is run.
+type Extension struct {
+	Name        string            `yaml:"name"`
+	Image       string            `yaml:"image"`
+	Args        []string          `yaml:"args,omitempty"`
+	Env         map[string]string `yaml:"env,omitempty"`
+	Mounts      []*ExtensionMount `yaml:"mounts,omitempty"`
+	Restart     string            `yaml:"restart,omitempty"`
+	MemoryLimit int64             `yaml:"memoryLimit,omitempty"`
+	DependsOn   []string          `yaml:"dependsOn,omitempty"`
+}
+
+// ExtensionMount describes a mount of an extension. The type defaults to
+// "bind", and the options default to a recursive, read-only bind mount.
+type ExtensionMount struct {
+	Source      string   `yaml:"source"`
+	Destination string   `yaml:"destination"`
+	Type        string   `yaml:"type,omitempty"`
+	Options     []string `yaml:"options,omitempty"`
 }
 
 // File represents a files to write to disk.
@@ -106,7 +133,63 @@ type InstallDevice struct {
 
 // Init describes the configuration of the init service.
 type Init struct {
-	CNI string `yaml:"cni,omitempty"`
+	CNI       string     `yaml:"cni,omitempty"`
+	Logging   *Logging   `yaml:"logging,omitempty"`
+	CrashLoop *CrashLoop `yaml:"crashLoop,omitempty"`
+}
+
+// CrashLoop describes when a system service is considered to be crash
+// looping, and what init does about it. The policy applies to every service,
+// and Services overrides it per service ID.
+type CrashLoop struct {
+	CrashLoopPolicy `yaml:",inline"`
+
+	Services map[string]*CrashLoopPolicy `yaml:"services,omitempty"`
+}
+
+// CrashLoopPolicy describes a crash loop policy. A service is crash looping
+// once it exits Threshold times within Window, for example "5m". The action
+// is one of "backoff", which keeps restarting the service at the maximum
+// backoff, "stop", which leaves the service stopped, or "reboot", which
+// reboots the node. Zero values leave the defaults in place.
+type CrashLoopPolicy struct {
+	Threshold int    `yaml:"threshold,omitempty"`
+	Window    string `yaml:"window,omitempty"`
+	Action    string `yaml:"action,omitempty"`
+}
+
+// Logging describes the rotation and the retention of the service logs in
+// /var/log, and the sinks that the logs are forwarded to. Sizes are in bytes,
+// and zero values leave the defaults in place.
+type Logging struct {
+	MaxSize      int64 `yaml:"maxSize,omitempty"`
+	Generations  *int  `yaml:"generations,omitempty"`
+	Compress     *bool `yaml:"compress,omitempty"`
+	ServiceQuota int64 `yaml:"serviceQuota,omitempty"`
+	Budget       int64 `yaml:"budget,omitempty"`
+
+	Sinks []*LogSink `yaml:"sinks,omitempty"`
+}
+
+// LogSink describes a remote endpoint that the node's logs are forwarded to.
+// The format is either "syslog" (RFC 5424) or "json" (newline delimited
+// JSON). Syslog may be sent over "udp" or "tcp", and JSON over "tcp". Records
+// are buffered on disk, up to BufferSize bytes, while the sink is unreachable.
+type LogSink struct {
+	Format     string      `yaml:"format"`
+	Protocol   string      `yaml:"protocol"`
+	Endpoint   string      `yaml:"endpoint"`
+	TLS        *LogSinkTLS `yaml:"tls,omitempty"`
+	BufferSize int64       `yaml:"bufferSize,omitempty"`
+}
+
+// LogSinkTLS describes the TLS settings used to connect to a TCP log sink. The
+// CA is a PEM encoded bundle of certificates trusted in addition to the
+// system roots.
+type LogSinkTLS struct {
+	CA                 string `yaml:"ca,omitempty"`
+	ServerName         string `yaml:"serverName,omitempty"`
+	InsecureSkipVerify bool   `yaml:"insecureSkipVerify,omitempty"`
 }
 
 // Kubelet describes the configuration of the kubelet service.
@@ -231,9 +314,82 @@ type Blockd struct {
 	CommonServiceOptions `yaml:",inline"`
 }
 
-// CRT describes the configuration of the container runtime service.
+// CRT describes the configuration of the container runtime service. Images
+// maps the image references used by the system services to the references
+// that should be used instead, and is typically used to pin an image to a
+// digest, for example:
+//
+//  k8s.gcr.io/hyperkube:v1.13.3: registry.local/hyperkube@sha256:...
+//
+// Images apply only to the services run by init, and not to pods.
 type CRT struct {
 	CommonServiceOptions `yaml:",inline"`
+
+	Registries *Registries       `yaml:"registries,omitempty"`
+	Images     map[string]string `yaml:"images,omitempty"`
+}
+
+// Registries describes how images that are missing locally are pulled.
+// Mirrors is keyed by the hostname of a registry, and Config is keyed by the
+// hostname of a registry or a mirror. Only the images pulled by init for the
+// services it runs are affected. The images of pods are pulled through CRI,
+// which ignores the registries.
+type Registries struct {
+	Mirrors map[string]*RegistryMirror `yaml:"mirrors,omitempty"`
+	Config  map[string]*RegistryConfig `yaml:"config,omitempty"`
+}
+
+// RegistryMirror describes the endpoints to try, in order, before the
+// registry itself. An endpoint is a URL such as https://mirror.local:5000.
+type RegistryMirror struct {
+	Endpoints []string `yaml:"endpoints"`
+}
+
+// RegistryConfig describes the credentials and the TLS settings of a
+// registry.
+type RegistryConfig struct {
+	Auth *RegistryAuth `yaml:"auth,omitempty"`
+	TLS  *RegistryTLS  `yaml:"tls,omitempty"`
+}
+
+// RegistryAuth describes the credentials used to authenticate with a
+// registry. Either a username and password, the base64 encoded
+// "username:password" pair, or an identity token may be set.
+type RegistryAuth struct {
+	Username      string `yaml:"username,omitempty"`
+	Password      string `yaml:"password,omitempty"`
+	Auth          string `yaml:"auth,omitempty"`
+	IdentityToken string `yaml:"identityToken,omitempty"`
+}
+
+// RegistryTLS describes the TLS settings used to connect to a registry. The
+// CA is a PEM encoded bundle of certificates trusted in addition to the
+// system roots.
+type RegistryTLS struct {
+	CA                 string `yaml:"ca,omitempty"`
+	InsecureSkipVerify bool   `yaml:"insecureSkipVerify,omitempty"`
+}
+
+// Image returns the reference that should be used for the image, according
+// to the CRT configuration.
+func (data *UserData) Image(ref string) string {
+	if data.Services == nil || data.Services.CRT == nil {
+		return ref
+	}
+	if override, ok := data.Services.CRT.Images[ref]; ok {
+		return override
+	}
+
+	return ref
+}
+
+// Registries returns the registry configuration, or nil if there is none.
+func (data *UserData) Registries() *Registries {
+	if data.Services == nil || data.Services.CRT == nil {
+		return nil
+	}
+
+	return data.Services.CRT.Registries
 }
 
 // CommonServiceOptions represents the set of options common to all services.
@@ -279,9 +435,11 @@ func (data *UserData) NewIdentityCSR() (csr *x509.CertificateSigningRequest, err
 	if err != nil {
 		return nil, err
 	}
-	for _, san := range data.Services.Trustd.CertSANs {
-		if ip := stdlibnet.ParseIP(san); ip != nil {
-			ips = append(ips, ip)
+	if data.Services != nil && data.Services.Trustd != nil {
+		for _, san := range data.Services.Trustd.CertSANs {
+			if ip := stdlibnet.ParseIP(san); ip != nil {
+				ips = append(ips, ip)
+			}
 		}
 	}
 	hostname, err := os.Hostname()